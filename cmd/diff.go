@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd compares two documents statement by statement
+var diffCmd = &cobra.Command{
+	Use:   "diff FIRST SECOND",
+	Short: "Show the statements that differ between two documents",
+	Long: `Gron two documents (from files, URLs, or stdin given as "-") and show the statements that were added (+), removed (-) or changed (~) between them.
+
+The exit status is 0 if the documents are the same, 1 if they differ and greater than 1 if there was a problem.
+
+With --patch the differences are written as a JSON Patch that can be applied with "gron patch".
+
+Examples:
+  gron diff two.json two-b.json
+  gron diff --patch two.json two-b.json > changes.json
+  curl -s http://jsonplaceholder.typicode.com/users/1 | gron diff - expected.json
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		patchFlag, err := cmd.Flags().GetBool("patch")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		if isStdin(args[0]) && isStdin(args[1]) {
+			log.Println("only one input can be read from stdin")
+			os.Exit(2)
+		}
+
+		first, err := openInput(args[0], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(2)
+		}
+		second, err := openInput(args[1], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(2)
+		}
+		firstFormat, err := inputFormat(args[0], fromFlag, yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		secondFormat, err := inputFormat(args[1], fromFlag, yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		action := internal.Diff
+		if patchFlag {
+			action = internal.DiffToPatch
+		}
+
+		actionExit, actionErr := action(
+			first,
+			second,
+			colorable.NewColorableStdout(),
+			firstFormat,
+			secondFormat,
+			useColor(colorizeFlag, monochromeFlag),
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	diffCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	diffCmd.Flags().StringP("from", "", "", "Read the inputs as json, json5, yaml, csv, tsv or xml (default from each file name, json otherwise)")
+	diffCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	diffCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	diffCmd.Flags().BoolP("patch", "p", false, "Write the differences as an RFC 6902 JSON Patch")
+	diffCmd.Flags().BoolP("yaml", "y", false, "Treat inputs as YAML instead of JSON")
+	rootCmd.AddCommand(diffCmd)
+}
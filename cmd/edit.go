@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/spf13/cobra"
+)
+
+// editCmd edits a document as gron statements in the user's editor
+var editCmd = &cobra.Command{
+	Use:   "edit FILE",
+	Short: "Edit a JSON or YAML file as gron statements in $EDITOR",
+	Long: `Gron a JSON or YAML file into a temporary file, open it in $VISUAL or $EDITOR, and when the editor exits ungron the statements and write the result back to the file in its original format.
+
+If the edited statements can't be ungronned the problem is reported and the editor is opened again at the offending line. If nothing was changed the file is left alone.
+
+Examples:
+  gron edit config.json
+  EDITOR=nano gron edit values.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		backupFlag, err := cmd.Flags().GetString("backup")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		filename := args[0]
+		format, err := inputFormat(filename, "", yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		os.Exit(editFile(filename, format == internal.InputYAML, backupFlag))
+	},
+}
+
+// editFile grons a file into a temporary file, edits it and writes the
+// result back. The temporary file holds the document's data, so it's
+// removed before returning on every path. Interrupts are caught for as
+// long as it runs so that Ctrl-C reaches the editor without killing
+// gron before it can clean up. It returns the exit code
+func editFile(filename string, inYaml bool, backup string) int {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	tmp, err := os.CreateTemp("", filepath.Base(filename)+".*.gron")
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer os.Remove(tmp.Name())
+
+	format := internal.InputJSON
+	if inYaml {
+		format = internal.InputYAML
+	}
+	gronned := &bytes.Buffer{}
+	actionExit, actionErr := internal.Gron(
+		bytes.NewReader(original),
+		gronned,
+		internal.StatementToString,
+		format,
+		false,
+		false,
+		internal.GronOptions{YAMLTags: inYaml},
+	)
+	if actionErr != nil {
+		_ = tmp.Close()
+		log.Println(actionErr)
+		return actionExit
+	}
+	_, err = tmp.Write(gronned.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	return editLoop(filename, tmp.Name(), original, gronned.Bytes(), inYaml, backup, interrupted)
+}
+
+// editLoop opens the editor on the statements in tmpName until they
+// can be ungronned or the user gives up, then writes the result back
+// to the file. Ctrl-C at the prompt to edit again, received on
+// interrupted, gives up. It returns the exit code
+func editLoop(filename, tmpName string, original, gronned []byte, inYaml bool, backup string, interrupted <-chan os.Signal) int {
+	stdin := bufio.NewReader(os.Stdin)
+	line := 0
+	for {
+		err := runEditor(tmpName, line)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+
+		edited, err := os.ReadFile(tmpName)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		if bytes.Equal(edited, gronned) {
+			log.Println("no changes made")
+			return 0
+		}
+
+		// YAML is written back over the original to keep its comments
+		var originalYaml io.Reader
+		if inYaml {
+			originalYaml = bytes.NewReader(original)
+		}
+		out := &bytes.Buffer{}
+		actionExit, actionErr := internal.UngronDocument(bytes.NewReader(edited), out, inYaml, originalYaml)
+		if actionErr == nil {
+			err = writeInPlace(filename, original, out.Bytes(), backup)
+			if err != nil {
+				log.Println(err)
+				return 1
+			}
+			return 0
+		}
+
+		log.Println(actionErr)
+		var se *internal.StatementError
+		if errors.As(actionErr, &se) {
+			line = se.Line
+		}
+
+		if !promptRetry(stdin, interrupted) {
+			return actionExit
+		}
+	}
+}
+
+// promptRetry asks whether to edit the statements again and returns
+// false if the user aborts with Ctrl-C or closes stdin. Interrupts sent
+// to the editor before the prompt are ignored
+func promptRetry(stdin *bufio.Reader, interrupted <-chan os.Signal) bool {
+	for len(interrupted) > 0 {
+		<-interrupted
+	}
+
+	answered := make(chan error, 1)
+	go func() {
+		_, err := stdin.ReadString('\n')
+		answered <- err
+	}()
+
+	fmt.Fprint(os.Stderr, "Press Enter to edit again or Ctrl-C to abort: ")
+	select {
+	case err := <-answered:
+		return err == nil
+	case <-interrupted:
+		fmt.Fprintln(os.Stderr)
+		return false
+	}
+}
+
+// runEditor opens a file in the user's editor, at the given line if it's
+// greater than zero, and waits for the editor to exit
+func runEditor(filename string, line int) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := editorArgs(strings.Fields(editor), filename, line)
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// editorArgs returns the command line that opens a file in an editor,
+// using the editor's own syntax for jumping to a line if line > 0
+func editorArgs(editor []string, filename string, line int) []string {
+	args := append([]string{}, editor...)
+	if line <= 0 {
+		return append(args, filename)
+	}
+
+	switch filepath.Base(editor[0]) {
+	case "code", "code-insiders", "codium":
+		return append(args, "--goto", fmt.Sprintf("%s:%d", filename, line))
+	case "subl", "zed", "hx":
+		return append(args, fmt.Sprintf("%s:%d", filename, line))
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and
+		// many others all understand +LINE
+		return append(args, fmt.Sprintf("+%d", line), filename)
+	}
+}
+
+func init() {
+	editCmd.Flags().StringP("backup", "b", ".bak", "Suffix of the backup file written before saving; empty for no backup")
+	editCmd.Flags().BoolP("yaml", "y", false, "Treat input as YAML instead of JSON (default for .yaml and .yml files)")
+	rootCmd.AddCommand(editCmd)
+}
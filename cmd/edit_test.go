@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		editor []string
+		line   int
+		want   []string
+	}{
+		{[]string{"vim"}, 0, []string{"vim", "f.gron"}},
+		{[]string{"vim"}, 3, []string{"vim", "+3", "f.gron"}},
+		{[]string{"/usr/bin/nano", "-l"}, 12, []string{"/usr/bin/nano", "-l", "+12", "f.gron"}},
+		{[]string{"code", "--wait"}, 5, []string{"code", "--wait", "--goto", "f.gron:5"}},
+		{[]string{"subl", "-w"}, 7, []string{"subl", "-w", "f.gron:7"}},
+	}
+
+	for _, test := range tests {
+		have := editorArgs(test.editor, "f.gron", test.line)
+		if !reflect.DeepEqual(have, test.want) {
+			t.Errorf("Want %v for editorArgs(%v, %d); have %v", test.want, test.editor, test.line, have)
+		}
+	}
+}
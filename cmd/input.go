@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+)
+
+// openInput opens the named input for reading. An empty name or "-"
+// means stdin, and names that look like URLs are fetched over HTTP
+func openInput(name string, insecure bool) (io.Reader, error) {
+	if isStdin(name) {
+		return os.Stdin, nil
+	}
+	if validURL(name) {
+		return getURL(name, insecure)
+	}
+	return os.Open(name)
+}
+
+// inputFormat returns the format to read the named input in: the one
+// named by from if it isn't empty, YAML if asYaml is set, XML if asXML
+// is set, YAML, CSV, TSV, XML or JSON5 for files with those extensions
+// or that are known to hold JSON with comments, and JSON otherwise
+func inputFormat(name string, from string, asYaml bool, asXML bool) (internal.InputFormat, error) {
+	if from != "" {
+		return internal.InputFormatFromString(from)
+	}
+	if asYaml {
+		return internal.InputYAML, nil
+	}
+	if asXML {
+		return internal.InputXML, nil
+	}
+	if isJSONCFile(name) {
+		return internal.InputJSON5, nil
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return internal.InputYAML, nil
+	case ".csv":
+		return internal.InputCSV, nil
+	case ".tsv":
+		return internal.InputTSV, nil
+	case ".xml":
+		return internal.InputXML, nil
+	case ".json5", ".jsonc":
+		return internal.InputJSON5, nil
+	default:
+		return internal.InputJSON, nil
+	}
+}
+
+// isJSONCFile returns true if the filename is one that's usually JSON
+// with comments and trailing commas, even though it ends in .json; e.g.
+// tsconfig.json, devcontainer.json and VS Code's .vscode/settings.json
+func isJSONCFile(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	if filepath.Ext(base) != ".json" {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(base, "tsconfig"), strings.HasPrefix(base, "jsconfig"):
+		return true
+	case base == "devcontainer.json", base == ".devcontainer.json":
+		return true
+	default:
+		return filepath.Base(filepath.Dir(filename)) == ".vscode"
+	}
+}
+
+// isStdin returns true if the input name refers to stdin
+func isStdin(name string) bool {
+	return name == "" || name == "-"
+}
+
+// useColor decides whether output should be colorized. Colorization
+// is forced by the colorize flag, disabled by the monochrome flag, and
+// otherwise on unless the NO_COLOR environment variable is set
+func useColor(colorizeFlag bool, monochromeFlag bool) bool {
+	if colorizeFlag {
+		return true
+	}
+	if monochromeFlag {
+		return false
+	}
+	nocolorEnv, nocolorEnvPresent := os.LookupEnv("NO_COLOR")
+	return !nocolorEnvPresent || nocolorEnv == ""
+}
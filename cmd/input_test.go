@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+)
+
+func TestInputFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		asYaml bool
+		asXML  bool
+		want   internal.InputFormat
+	}{
+		{"data.json", "", false, false, internal.InputJSON},
+		{"", "", false, false, internal.InputJSON},
+		{"data.json", "", true, false, internal.InputYAML},
+		{"data.json", "csv", true, false, internal.InputCSV},
+		{"users.CSV", "", false, false, internal.InputCSV},
+		{"users.tsv", "", false, false, internal.InputTSV},
+		{"feed.xml", "", false, false, internal.InputXML},
+		{"-", "", false, true, internal.InputXML},
+		{"config.json5", "", false, false, internal.InputJSON5},
+		{"tsconfig.json", "", false, false, internal.InputJSON5},
+		{"web/tsconfig.build.json", "", false, false, internal.InputJSON5},
+		{".devcontainer/devcontainer.json", "", false, false, internal.InputJSON5},
+		{"project/.vscode/settings.json", "", false, false, internal.InputJSON5},
+		{"settings.json", "", false, false, internal.InputJSON},
+		{"tsconfig.yaml", "", false, false, internal.InputYAML},
+		{"values.YML", "", false, false, internal.InputYAML},
+		{"values.yaml", "json", false, false, internal.InputJSON},
+	}
+
+	for _, test := range tests {
+		have, err := inputFormat(test.name, test.from, test.asYaml, test.asXML)
+		if err != nil {
+			t.Fatalf("Want no error for inputFormat(%s); have %s", test.name, err)
+		}
+		if have != test.want {
+			t.Errorf("Want %d for inputFormat(%s); have %d", test.want, test.name, have)
+		}
+	}
+
+	_, err := inputFormat("data.json", "toml", false, false)
+	if err == nil {
+		t.Errorf("Want an error for an unknown input format; have none")
+	}
+}
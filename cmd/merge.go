@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd merges several documents into one
+var mergeCmd = &cobra.Command{
+	Use:   "merge FILE...",
+	Short: "Merge several documents into one",
+	Long: `Merge documents in any input format (from files, URLs, or stdin given as "-") in order and write the result as JSON.
+
+Objects are merged key by key. Where two documents have different values at the same path the conflict policy decides which is kept: "last" (the default), "first", or "error" to fail listing every conflicting path. Files ending in .yaml or .yml are read as YAML.
+
+Arrays are merged according to the array strategy:
+  index    merge the elements at each index (the default)
+  replace  keep one array or the other, according to the conflict policy
+  concat   append the elements of the later array
+  key      merge objects with the same value for --array-key
+
+Examples:
+  gron merge defaults.json overrides.yaml
+  gron merge --conflicts error --arrays key --array-key name a.json b.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		arrayKeyFlag, err := cmd.Flags().GetString("array-key")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		arraysFlag, err := cmd.Flags().GetString("arrays")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		conflictsFlag, err := cmd.Flags().GetString("conflicts")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		outputYamlFlag, err := cmd.Flags().GetBool("output-yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		reportFlag, err := cmd.Flags().GetBool("report")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		var opts internal.MergeOptions
+		opts.Conflicts, err = internal.ConflictPolicyFromString(conflictsFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		opts.Arrays, err = internal.ArrayStrategyFromString(arraysFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		if arrayKeyFlag != "" {
+			if cmd.Flags().Changed("arrays") && opts.Arrays != internal.ArrayMergeByKey {
+				fmt.Printf("--array-key cannot be used with --arrays %s\n", arraysFlag)
+				os.Exit(-1)
+			}
+			opts.Arrays = internal.ArrayMergeByKey
+			opts.ArrayKey = arrayKeyFlag
+		}
+		if opts.Arrays == internal.ArrayMergeByKey && opts.ArrayKey == "" {
+			fmt.Println("--arrays key needs --array-key")
+			os.Exit(-1)
+		}
+
+		var stdinUsed bool
+		inputs := make([]io.Reader, len(args))
+		formats := make([]internal.InputFormat, len(args))
+		for i, name := range args {
+			if isStdin(name) {
+				if stdinUsed {
+					log.Println("only one input can be read from stdin")
+					os.Exit(1)
+				}
+				stdinUsed = true
+			}
+			inputs[i], err = openInput(name, insecureFlag)
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			formats[i], err = inputFormat(name, fromFlag, yamlFlag, false)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(-1)
+			}
+		}
+
+		var report io.Writer
+		if reportFlag || opts.Conflicts == internal.ConflictError {
+			report = os.Stderr
+		}
+
+		actionExit, actionErr := internal.Merge(
+			inputs,
+			formats,
+			colorable.NewColorableStdout(),
+			report,
+			opts,
+			useColor(colorizeFlag, monochromeFlag) && !outputYamlFlag,
+			outputYamlFlag,
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringP("array-key", "K", "", "Merge arrays of objects by this field (implies --arrays key; an error with any other --arrays)")
+	mergeCmd.Flags().StringP("arrays", "a", "index", "How to merge arrays: index, replace, concat or key")
+	mergeCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	mergeCmd.Flags().String("conflicts", "last", "Which value to keep on conflict: last, first or error")
+	mergeCmd.Flags().StringP("from", "", "", "Read the inputs as json, json5, yaml, csv, tsv or xml (default from each file name, json otherwise)")
+	mergeCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	mergeCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	mergeCmd.Flags().BoolP("output-yaml", "Y", false, "Write the merged document as YAML")
+	mergeCmd.Flags().BoolP("report", "r", false, "Report conflicting paths on stderr")
+	mergeCmd.Flags().BoolP("yaml", "y", false, "Treat all inputs as YAML instead of JSON")
+	rootCmd.AddCommand(mergeCmd)
+}
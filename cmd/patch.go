@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// patchCmd applies a JSON Patch to a document
+var patchCmd = &cobra.Command{
+	Use:   "patch DOCUMENT PATCH",
+	Short: "Apply an RFC 6902 JSON Patch to a document",
+	Long: `Apply the operations of an RFC 6902 JSON Patch to a document (from a file, URL, or stdin given as "-") and write the result as JSON.
+
+Examples:
+  gron diff --patch two.json two-b.json > changes.json
+  gron patch two.json changes.json
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		if isStdin(args[0]) && isStdin(args[1]) {
+			log.Println("only one input can be read from stdin")
+			os.Exit(1)
+		}
+
+		doc, err := openInput(args[0], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		patch, err := openInput(args[1], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		format, err := inputFormat(args[0], fromFlag, yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		actionExit, actionErr := internal.Patch(
+			doc,
+			patch,
+			colorable.NewColorableStdout(),
+			format,
+			useColor(colorizeFlag, monochromeFlag),
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	patchCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	patchCmd.Flags().StringP("from", "", "", "Read the document as json, json5, yaml, csv, tsv or xml (default from the file name, json otherwise); the patch is always JSON")
+	patchCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	patchCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	patchCmd.Flags().BoolP("yaml", "y", false, "Treat the document as YAML instead of JSON")
+	rootCmd.AddCommand(patchCmd)
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	internal "github.com/lafrenierejm/gron/internal/gron"
 	"github.com/mattn/go-colorable"
@@ -34,11 +35,51 @@ Examples:
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		asciiFlag, err := cmd.Flags().GetBool("ascii")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		colorizeFlag, err := cmd.Flags().GetBool("colorize")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		csvPathsFlag, err := cmd.Flags().GetBool("csv-paths")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		csvTypesFlag, err := cmd.Flags().GetBool("csv-types")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		embeddedJSONFlag, err := cmd.Flags().GetBool("embedded-json")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		compactFlag, err := cmd.Flags().GetBool("compact")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		conflictsFlag, err := cmd.Flags().GetString("conflicts")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		indentFlag, err := cmd.Flags().GetInt("indent")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		insecureFlag, err := cmd.Flags().GetBool("insecure")
 		if err != nil {
 			fmt.Println(err)
@@ -49,21 +90,56 @@ Examples:
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		linesFlag, err := cmd.Flags().GetBool("lines")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		maxIndexFlag, err := cmd.Flags().GetInt("max-index")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		pathFlag, err := cmd.Flags().GetString("path")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		pathFormatFlag, err := cmd.Flags().GetString("path-format")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		sortFlag, err := cmd.Flags().GetBool("sort")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		sortKeysFlag, err := cmd.Flags().GetBool("sort-keys")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		sparseFlag, err := cmd.Flags().GetString("sparse")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		streamFlag, err := cmd.Flags().GetBool("stream")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		tabFlag, err := cmd.Flags().GetBool("tab")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		ungronFlag, err := cmd.Flags().GetBool("ungron")
 		if err != nil {
 			fmt.Println(err)
@@ -74,48 +150,179 @@ Examples:
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		maxArrayFlag, err := cmd.Flags().GetInt("max-array")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		maxStringLengthFlag, err := cmd.Flags().GetInt("max-string-length")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		redactFlag, err := cmd.Flags().GetBool("redact")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		redactKeyFlag, err := cmd.Flags().GetStringArray("redact-key")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		redactPathFlag, err := cmd.Flags().GetStringArray("redact-path")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		reportFlag, err := cmd.Flags().GetBool("report")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		schemaFlag, err := cmd.Flags().GetBool("schema")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		toFlag, err := cmd.Flags().GetString("to")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		typeNameFlag, err := cmd.Flags().GetString("type-name")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 		yamlFlag, err := cmd.Flags().GetBool("yaml")
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(-1)
 		}
+		yamlTagsFlag, err := cmd.Flags().GetBool("yaml-tags")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		xmlFlag, err := cmd.Flags().GetBool("xml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
 
-		var rawInput io.Reader
-		if len(args) == 0 || args[0] == "" || args[0] == "-" {
-			rawInput = os.Stdin
-		} else {
-			filename := args[0]
-			if validURL(filename) {
-				rawInput, err = getURL(filename, insecureFlag)
-				if err != nil {
-					log.Println(err)
-					os.Exit(1)
-				}
-			} else {
-				rawInput, err = os.Open(filename)
-				if err != nil {
-					log.Println(err)
-					os.Exit(1)
-				}
-			}
+		var filename string
+		if len(args) > 0 {
+			filename = args[0]
+		}
+		rawInput, err := openInput(filename, insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
 		}
 
-		var conv internal.StatementConv = internal.StatementToString
-		var colorize bool = false
-		if colorizeFlag {
-			colorize = true
-		} else if !monochromeFlag {
-			nocolorEnv, nocolorEnvPresent := os.LookupEnv("NO_COLOR")
-			if nocolorEnvPresent && nocolorEnv != "" {
-				colorize = false
-			} else {
-				colorize = true
-			}
+		format, err := inputFormat(filename, fromFlag, yamlFlag, xmlFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		if (format == internal.InputCSV || format == internal.InputTSV || format == internal.InputXML) && streamFlag {
+			fmt.Println("csv, tsv and xml input cannot be used with --stream")
+			os.Exit(-1)
 		}
+
+		var conv internal.StatementConv = internal.StatementToString
+		colorize := useColor(colorizeFlag, monochromeFlag)
 		if colorize {
 			conv = internal.StatementToColorString
 		}
 
+		pathFormat, err := internal.PathFormatFromString(pathFormatFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		if pathFormat != internal.PathGron {
+			if jsonFlag {
+				log.Println("--path-format cannot be used with --json")
+				os.Exit(1)
+			}
+			if ungronFlag {
+				log.Println("--path-format cannot be used with --ungron")
+				os.Exit(1)
+			}
+			conv = internal.StatementToPathConv(pathFormat, colorize)
+		}
+
+		ungronOpts := internal.UngronOptions{
+			Compact:  compactFlag,
+			Indent:   strings.Repeat(" ", indentFlag),
+			SortKeys: sortKeysFlag,
+			ASCII:    asciiFlag,
+			Lines:    linesFlag,
+			MaxIndex: maxIndexFlag,
+		}
+		ungronOpts.Sparse, err = internal.SparseModeFromString(sparseFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		if tabFlag {
+			ungronOpts.Indent = "\t"
+		} else if indentFlag <= 0 {
+			ungronOpts.Compact = true
+		}
+		ungronOpts.Conflicts, err = internal.ConflictPolicyFromString(conflictsFlag)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		if reportFlag {
+			ungronOpts.Warnings = os.Stderr
+		}
+
+		gronOpts := internal.GronOptions{
+			YAMLTags:        yamlTagsFlag,
+			EmbeddedJSON:    embeddedJSONFlag,
+			MaxArray:        maxArrayFlag,
+			MaxStringLength: maxStringLengthFlag,
+			TableTypes:      csvTypesFlag,
+			TablePaths:      csvPathsFlag,
+		}
+		if redactFlag || len(redactKeyFlag) > 0 || len(redactPathFlag) > 0 {
+			gronOpts.Redact, err = internal.NewRedactor(redactKeyFlag, redactPathFlag)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(-1)
+			}
+		}
+
+		var outputFormat internal.OutputFormat
+		if toFlag != "" {
+			outputFormat, err = internal.OutputFormatFromString(toFlag)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(-1)
+			}
+			isDocument := outputFormat == internal.OutputYAML || outputFormat == internal.OutputXML
+			if ungronFlag && !isDocument {
+				fmt.Printf("--to %s cannot be used with --ungron\n", toFlag)
+				os.Exit(-1)
+			}
+			if !ungronFlag && isDocument {
+				fmt.Printf("--to %s can only be used with --ungron\n", toFlag)
+				os.Exit(-1)
+			}
+		}
+		if outputFormat == internal.OutputYAML || outputFormat == internal.OutputXML {
+			if linesFlag {
+				fmt.Printf("--to %s cannot be used with --lines\n", toFlag)
+				os.Exit(-1)
+			}
+			ungronOpts.YAML = outputFormat == internal.OutputYAML
+			ungronOpts.XML = outputFormat == internal.OutputXML
+		}
+
 		var actionExit int
 		var actionErr error
 		if ungronFlag {
@@ -124,6 +331,43 @@ Examples:
 				colorable.NewColorableStdout(),
 				jsonFlag,
 				colorize,
+				ungronOpts,
+			)
+		} else if toFlag != "" {
+			switch outputFormat {
+			case internal.OutputCSV, internal.OutputTSV:
+				comma := ','
+				if outputFormat == internal.OutputTSV {
+					comma = '\t'
+				}
+				actionExit, actionErr = internal.WriteTable(
+					rawInput,
+					colorable.NewColorableStdout(),
+					format,
+					pathFlag,
+					comma,
+					gronOpts,
+				)
+			default:
+				actionExit, actionErr = internal.WriteTypes(
+					rawInput,
+					colorable.NewColorableStdout(),
+					format,
+					streamFlag,
+					outputFormat,
+					typeNameFlag,
+					gronOpts,
+				)
+			}
+		} else if schemaFlag {
+			actionExit, actionErr = internal.SampleSchema(
+				[]io.Reader{rawInput},
+				[]internal.InputFormat{format},
+				colorable.NewColorableStdout(),
+				conv,
+				colorize,
+				internal.SchemaOptions{Sort: sortFlag},
+				gronOpts,
 			)
 		} else if valuesFlag {
 			actionExit, actionErr = gronValues(rawInput, colorable.NewColorableStdout())
@@ -132,23 +376,25 @@ Examples:
 				rawInput,
 				colorable.NewColorableStdout(),
 				conv,
-				yamlFlag,
+				format,
 				sortFlag,
 				jsonFlag,
+				gronOpts,
 			)
 		} else {
 			actionExit, actionErr = internal.Gron(
 				rawInput,
 				colorable.NewColorableStdout(),
 				conv,
-				yamlFlag,
+				format,
 				sortFlag,
 				jsonFlag,
+				gronOpts,
 			)
 		}
 
 		if actionExit != 0 || actionErr != nil {
-			log.Println(err)
+			log.Println(actionErr)
 		}
 		os.Exit(actionExit)
 	},
@@ -164,16 +410,42 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.Flags().BoolP("ascii", "", false, "Escape non-ASCII characters when ungronning")
 	rootCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	rootCmd.Flags().BoolP("compact", "", false, "Write ungronned JSON on a single line")
+	rootCmd.Flags().StringP("conflicts", "", "last", "Which value to keep on conflicting assignments when ungronning: last, first or error")
+	rootCmd.Flags().BoolP("csv-paths", "", false, "Read CSV and TSV headers like address.city as paths to nested values instead of as keys")
+	rootCmd.Flags().BoolP("csv-types", "", false, "Read CSV and TSV cells holding numbers, booleans and other JSON values as those values instead of as strings")
+	rootCmd.Flags().BoolP("embedded-json", "", false, "Decode JSON objects and arrays held in string values")
+	rootCmd.Flags().StringP("from", "", "", "Read the input as json, json5, yaml, csv, tsv or xml (default from the file name, json otherwise)")
+	rootCmd.Flags().IntP("indent", "", 2, "Number of spaces to indent ungronned JSON by")
 	rootCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
 	rootCmd.Flags().BoolP("json", "j", false, "Represent gron data as JSON stream")
+	rootCmd.Flags().BoolP("lines", "", false, "Ungron a top-level array into one JSON document per line")
+	rootCmd.Flags().IntP("max-array", "", 0, "Write only the first N elements of each array (0 for all)")
+	rootCmd.Flags().IntP("max-index", "", internal.DefaultMaxIndex, "Most missing array elements filled with null to reach an index when ungronning (-1 for no limit)")
+	rootCmd.Flags().IntP("max-string-length", "", 0, "Cut strings down to N characters, ending them with an ellipsis (0 for no limit)")
 	rootCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	rootCmd.Flags().StringP("path", "", "json", "Path of the array written as rows with --to csv or --to tsv")
+	rootCmd.Flags().StringP("path-format", "", "gron", "Write paths as gron, jsonpath, pointer (RFC 6901) or jq")
+	rootCmd.Flags().BoolP("redact", "", false, "Replace the values of keys like password, secret, token, authorization and api_key")
+	rootCmd.Flags().StringArrayP("redact-key", "", nil, "Also redact the values of keys matching a regular expression (implies --redact)")
+	rootCmd.Flags().StringArrayP("redact-path", "", nil, "Also redact the values at and beneath a path; e.g. json.users[0].email (implies --redact)")
+	rootCmd.Flags().BoolP("report", "", false, "Report conflicting assignments on stderr when ungronning")
+	rootCmd.Flags().BoolP("schema", "", false, "Print each path once with [*] for array indexes and the types found there, the same as gron schema")
 	rootCmd.Flags().BoolP("sort", "", false, "Sort output")
+	rootCmd.Flags().BoolP("sort-keys", "", false, "Sort object keys when ungronning")
+	rootCmd.Flags().StringP("sparse", "", "pad", "Ungron sparse arrays padded with nulls (pad), renumbered (compact) or as objects (object)")
 	rootCmd.Flags().BoolP("stream", "s", false, "Treat each line of input as a separate JSON object")
+	rootCmd.Flags().BoolP("tab", "", false, "Indent ungronned JSON with tabs")
+	rootCmd.Flags().StringP("to", "", "", "Write the input as go-struct or typescript definitions, or as a csv or tsv table; write yaml or xml when ungronning")
+	rootCmd.Flags().StringP("type-name", "", "Root", "Name of the type written for the whole input with --to")
 	rootCmd.Flags().BoolP("ungron", "u", false, "Reverse the operation (turn assignments back into JSON)")
 	rootCmd.Flags().BoolP("values", "v", false, "Print just the values of provided assignments")
 	rootCmd.Flags().BoolP("version", "", false, "Print version information")
-	rootCmd.Flags().BoolP("yaml", "y", false, "Treat input as YAML instead of JSON")
+	rootCmd.Flags().BoolP("xml", "", false, "Treat input as XML instead of JSON")
+	rootCmd.Flags().BoolP("yaml", "y", false, "Treat input as YAML instead of JSON (default for .yaml and .yml files)")
+	rootCmd.Flags().BoolP("yaml-tags", "", false, "Add a comment with the tag of YAML values whose tag is lost in gron")
 }
 
 // gronValues prints just the scalar values from some input gron statements
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd describes the shape of some sample documents
+var schemaCmd = &cobra.Command{
+	Use:   "schema [FILE...]",
+	Short: "Describe the shape of sample JSON or YAML documents",
+	Long: `Read sample JSON or YAML documents (from files, URLs, or stdin) and write each path found in them once, with [*] for array indexes, along with the types of the values found there.
+
+Every document in each input is a sample, so newline delimited JSON and YAML files with several documents can be given. Files ending in .yaml or .yml are read as YAML, and the other formats that gron reads are recognised by their file extension.
+
+With --json-schema a draft 2020-12 JSON Schema is written instead. Object keys are required when they're found in at least the --required fraction of objects.
+
+Examples:
+  gron schema response.json
+  gron schema --json-schema --required 0.9 events.ndjson
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		jsonSchemaFlag, err := cmd.Flags().GetBool("json-schema")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		requiredFlag, err := cmd.Flags().GetFloat64("required")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		if requiredFlag < 0 || requiredFlag > 1 {
+			fmt.Println("--required must be between 0 and 1")
+			os.Exit(-1)
+		}
+
+		if len(args) == 0 {
+			args = []string{"-"}
+		}
+
+		var stdinUsed bool
+		inputs := make([]io.Reader, len(args))
+		formats := make([]internal.InputFormat, len(args))
+		for i, name := range args {
+			if isStdin(name) {
+				if stdinUsed {
+					log.Println("only one input can be read from stdin")
+					os.Exit(1)
+				}
+				stdinUsed = true
+			}
+			inputs[i], err = openInput(name, insecureFlag)
+			if err != nil {
+				log.Println(err)
+				os.Exit(1)
+			}
+			formats[i], err = inputFormat(name, "", yamlFlag, false)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(-1)
+			}
+		}
+
+		colorize := useColor(colorizeFlag, monochromeFlag)
+		var conv internal.StatementConv = internal.StatementToString
+		if colorize {
+			conv = internal.StatementToColorString
+		}
+
+		actionExit, actionErr := internal.SampleSchema(
+			inputs,
+			formats,
+			colorable.NewColorableStdout(),
+			conv,
+			colorize,
+			internal.SchemaOptions{
+				JSONSchema: jsonSchemaFlag,
+				Required:   requiredFlag,
+			},
+			internal.GronOptions{},
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	schemaCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	schemaCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	schemaCmd.Flags().BoolP("json-schema", "", false, "Write a draft 2020-12 JSON Schema")
+	schemaCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	schemaCmd.Flags().Float64P("required", "", 1, "Fraction of objects a key must be found in to be required")
+	schemaCmd.Flags().BoolP("yaml", "y", false, "Treat all inputs as YAML instead of JSON")
+	rootCmd.AddCommand(schemaCmd)
+}
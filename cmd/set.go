@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/spf13/cobra"
+)
+
+// setCmd sets the value at a path in a JSON or YAML file
+var setCmd = &cobra.Command{
+	Use:   "set FILE PATH VALUE",
+	Short: "Set the value at a gron path in a JSON or YAML file",
+	Long: `Set the value at a gron path in a JSON or YAML file (or stdin given as "-") and write the result in the same format.
+
+VALUE is parsed as JSON, so objects, arrays, numbers and booleans can be given; anything that isn't valid JSON is used as a string. Objects and arrays along the path are created as needed.
+
+Examples:
+  gron set config.json 'json.server.port' 8080
+  gron set --in-place values.yaml 'json.image.tag' '"1.2"'
+`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		stringFlag, err := cmd.Flags().GetBool("string")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		runEdit(cmd, args[0], func(r io.Reader, w io.Writer, inYaml bool) (int, error) {
+			return internal.Set(r, w, args[1], args[2], inYaml, stringFlag)
+		})
+	},
+}
+
+// delCmd removes the value at a path in a JSON or YAML file
+var delCmd = &cobra.Command{
+	Use:   "del FILE PATH",
+	Short: "Delete the value at a gron path in a JSON or YAML file",
+	Long: `Delete the value at a gron path in a JSON or YAML file (or stdin given as "-") and write the result in the same format. Deleting an array element shifts the elements after it down by one.
+
+Examples:
+  gron del config.json 'json.server.debug'
+  gron del --in-place values.yaml 'json.ingress.tls[0]'
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runEdit(cmd, args[0], func(r io.Reader, w io.Writer, inYaml bool) (int, error) {
+			return internal.Del(r, w, args[1], inYaml)
+		})
+	},
+}
+
+// an editFn edits the document read from r, writing the result to w
+type editFn func(r io.Reader, w io.Writer, inYaml bool) (int, error)
+
+// runEdit runs an edit of the named file, writing the result to stdout
+// or back to the file itself depending on the command's flags
+func runEdit(cmd *cobra.Command, filename string, edit editFn) {
+	backupFlag, err := cmd.Flags().GetString("backup")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	inPlaceFlag, err := cmd.Flags().GetBool("in-place")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	yamlFlag, err := cmd.Flags().GetBool("yaml")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+
+	if inPlaceFlag && isStdin(filename) {
+		log.Println("--in-place cannot be used with stdin")
+		os.Exit(1)
+	}
+
+	format, err := inputFormat(filename, "", yamlFlag, false)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(-1)
+	}
+	inYaml := format == internal.InputYAML
+
+	var in io.Reader = os.Stdin
+	var original []byte
+	if !isStdin(filename) {
+		original, err = os.ReadFile(filename)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		in = bytes.NewReader(original)
+	}
+
+	if !inPlaceFlag {
+		actionExit, actionErr := edit(in, os.Stdout, inYaml)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	}
+
+	out := &bytes.Buffer{}
+	actionExit, actionErr := edit(in, out, inYaml)
+	if actionErr != nil {
+		log.Println(actionErr)
+		os.Exit(actionExit)
+	}
+
+	err = writeInPlace(filename, original, out.Bytes(), backupFlag)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	os.Exit(actionExit)
+}
+
+// writeInPlace replaces the contents of a file, first saving the original
+// contents to a backup file named with the suffix if it isn't empty.
+// The new contents are written to a temporary file which is then renamed
+// over the original so that a failed write doesn't leave a partial file
+func writeInPlace(filename string, original []byte, contents []byte, backupSuffix string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	if backupSuffix != "" {
+		err = os.WriteFile(filename+backupSuffix, original, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(contents)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{setCmd, delCmd} {
+		c.Flags().StringP("backup", "b", ".bak", "Suffix of the backup file written with --in-place; empty for no backup")
+		c.Flags().BoolP("in-place", "i", false, "Write the result back to the file instead of stdout")
+		c.Flags().BoolP("yaml", "y", false, "Treat input as YAML instead of JSON (default for .yaml and .yml files)")
+		rootCmd.AddCommand(c)
+	}
+	setCmd.Flags().BoolP("string", "s", false, "Use VALUE as a string instead of parsing it as JSON")
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd reports statistics about a document
+var statsCmd = &cobra.Command{
+	Use:   "stats [FILE]",
+	Short: "Show statistics about the size and shape of a document",
+	Long: `Read a document (from a file, URL, or stdin) and show the number of statements it grons to, the depth of its deepest path, the number of values of each type, its largest arrays and objects, its longest strings and the size of each top level key.
+
+The input is read as JSON, or in the format given by --from or the file extension the same way as by gron itself. Files ending in .yaml or .yml are read as YAML. Input with more than one document, such as newline delimited JSON, is an error.
+
+Examples:
+  gron stats response.json
+  curl -s http://jsonplaceholder.typicode.com/users | gron stats --top 3
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		topFlag, err := cmd.Flags().GetInt("top")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		name := "-"
+		if len(args) > 0 {
+			name = args[0]
+		}
+		input, err := openInput(name, insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		format, err := inputFormat(name, fromFlag, yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		var conv internal.StatementConv = internal.StatementToString
+		if useColor(colorizeFlag, monochromeFlag) {
+			conv = internal.StatementToColorString
+		}
+
+		actionExit, actionErr := internal.Stats(
+			input,
+			colorable.NewColorableStdout(),
+			conv,
+			format,
+			topFlag,
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	statsCmd.Flags().StringP("from", "", "", "Read the input as json, json5, yaml, csv, tsv or xml (default from the file name, json otherwise)")
+	statsCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	statsCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	statsCmd.Flags().IntP("top", "n", 5, "Number of the largest values to list (0 for all)")
+	statsCmd.Flags().BoolP("yaml", "y", false, "Treat input as YAML instead of JSON")
+	rootCmd.AddCommand(statsCmd)
+}
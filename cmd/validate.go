@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	internal "github.com/lafrenierejm/gron/internal/gron"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// validateSchemaCmd validates a document against a JSON Schema
+var validateSchemaCmd = &cobra.Command{
+	Use:   "validate-schema SCHEMA DOCUMENT",
+	Short: "Show the statements of a document that don't match a JSON Schema",
+	Long: `Validate a JSON or YAML document (from a file, URL, or stdin given as "-") against a JSON Schema and write the statement for each value that doesn't match, with a comment saying why.
+
+Schemas that don't name their dialect with $schema are read as draft 2020-12. Documents ending in .yaml or .yml are read as YAML.
+
+The exit status is 0 if the document is valid and greater than 0 if it isn't or there was a problem.
+
+Examples:
+  gron validate-schema schema.json payload.json
+  curl -s https://example.com/webhook.json | gron validate-schema --all schema.json -
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		allFlag, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		colorizeFlag, err := cmd.Flags().GetBool("colorize")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		insecureFlag, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		monochromeFlag, err := cmd.Flags().GetBool("monochrome")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		yamlFlag, err := cmd.Flags().GetBool("yaml")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		if isStdin(args[0]) && isStdin(args[1]) {
+			log.Println("only one input can be read from stdin")
+			os.Exit(1)
+		}
+		schema, err := openInput(args[0], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		doc, err := openInput(args[1], insecureFlag)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+
+		format, err := inputFormat(args[1], "", yamlFlag, false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+
+		var conv internal.StatementConv = internal.StatementToString
+		if useColor(colorizeFlag, monochromeFlag) {
+			conv = internal.StatementToColorString
+		}
+
+		actionExit, actionErr := internal.ValidateSchema(
+			schema,
+			args[0],
+			doc,
+			colorable.NewColorableStdout(),
+			conv,
+			format == internal.InputYAML,
+			allFlag,
+		)
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+		os.Exit(actionExit)
+	},
+}
+
+func init() {
+	validateSchemaCmd.Flags().BoolP("all", "a", false, "Write every statement of the document, not just those that don't match")
+	validateSchemaCmd.Flags().BoolP("colorize", "c", false, "Colorize output (default on TTY)")
+	validateSchemaCmd.Flags().BoolP("insecure", "k", false, "Disable certificate validation when reading from a URL")
+	validateSchemaCmd.Flags().BoolP("monochrome", "m", false, "Do not colorize output")
+	validateSchemaCmd.Flags().BoolP("yaml", "y", false, "Treat the document as YAML instead of JSON")
+	rootCmd.AddCommand(validateSchemaCmd)
+}
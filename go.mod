@@ -7,6 +7,7 @@ require (
 	github.com/mattn/go-colorable v0.1.13
 	github.com/nwidger/jsoncolor v0.3.2
 	github.com/pkg/errors v0.9.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/spf13/cobra v1.7.0
 	github.com/virtuald/go-ordered-json v0.0.0-20170621173500-b18e6e673d74
 	gopkg.in/yaml.v3 v3.0.1
@@ -13,6 +13,8 @@ var (
 	bareColor  = color.New(color.FgBlue, color.Bold)
 	numColor   = color.New(color.FgRed)
 	boolColor  = color.New(color.FgCyan)
+
+	commentColor = color.New(color.Faint)
 )
 
 // a sprintFn adds color to its input
@@ -22,6 +24,7 @@ type sprintFn func(...interface{}) string
 var sprintFns = map[TokenTyp]sprintFn{
 	TypBare:        bareColor.SprintFunc(),
 	TypNumericKey:  numColor.SprintFunc(),
+	TypWildcard:    numColor.SprintFunc(),
 	TypQuotedKey:   strColor.SprintFunc(),
 	TypLBrace:      braceColor.SprintFunc(),
 	TypRBrace:      braceColor.SprintFunc(),
@@ -32,11 +35,19 @@ var sprintFns = map[TokenTyp]sprintFn{
 	TypNull:        boolColor.SprintFunc(),
 	TypEmptyArray:  braceColor.SprintFunc(),
 	TypEmptyObject: braceColor.SprintFunc(),
+	TypDelete:      bareColor.SprintFunc(),
+	TypUndefined:   boolColor.SprintFunc(),
+	TypComment:     commentColor.SprintFunc(),
+	TypTypes:       boolColor.SprintFunc(),
 }
 
-func colorizeJSON(src []byte) ([]byte, error) {
+// colorizeJSON adds color to some encoded JSON, reformatting
+// it with the provided indentation; an empty indent means the
+// colorized JSON is written on a single line
+func colorizeJSON(src []byte, indent string) ([]byte, error) {
 	out := &bytes.Buffer{}
 	f := jsoncolor.NewFormatter()
+	f.Indent = indent
 
 	f.StringColor = strColor
 	f.ObjectColor = braceColor
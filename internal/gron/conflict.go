@@ -0,0 +1,156 @@
+package gron
+
+import (
+	"fmt"
+)
+
+// An AssignmentConflict is a statement that assigns a value to a path
+// that an earlier statement assigned a different value or type to.
+// E.g:
+//
+//	json.a = 1;
+//	json.a.b = 2;
+type AssignmentConflict struct {
+	Path  string
+	First int
+	Last  int
+
+	// replacesContainer is set when the earlier value was an object
+	// or array, which can't be merged with a different type of value
+	replacesContainer bool
+}
+
+// Error returns a description of the conflict without the line of the
+// later statement, which is given by the *StatementError wrapping it
+func (c AssignmentConflict) Error() string {
+	return fmt.Sprintf("%s was already assigned a different value on line %d", c.Path, c.First)
+}
+
+// String returns a description of the conflict with both line numbers
+func (c AssignmentConflict) String() string {
+	return fmt.Sprintf("line %d: %s", c.Last, c.Error())
+}
+
+// An assignment records the line that last assigned a path, and the
+// kind of value assigned: {} or [] for objects and arrays, including
+// those implied by longer paths, or the text of a scalar value. The
+// assignments beneath the path are kept by key so that forgetting
+// them doesn't mean looking at every other path
+type assignment struct {
+	line     int
+	kind     string
+	children map[interface{}]*assignment
+}
+
+// assignmentTracker finds statements that conflict with earlier ones
+type assignmentTracker struct {
+	root *assignment
+}
+
+// newAssignmentTracker returns an empty assignmentTracker
+func newAssignmentTracker() *assignmentTracker {
+	return &assignmentTracker{root: &assignment{}}
+}
+
+// assignmentKinds returns the kind of value that an assignment implies for
+// each prefix of its keys, which start with the leading bare word
+func assignmentKinds(keys []interface{}, value Token) []string {
+	kinds := make([]string, len(keys))
+	for i := range keys {
+		if i == len(keys)-1 {
+			switch value.Typ {
+			case TypEmptyObject:
+				kinds[i] = "{}"
+			case TypEmptyArray:
+				kinds[i] = "[]"
+			default:
+				kinds[i] = value.Text
+			}
+			continue
+		}
+
+		if _, ok := keys[i+1].(int); ok {
+			kinds[i] = "[]"
+		} else {
+			kinds[i] = "{}"
+		}
+	}
+	return kinds
+}
+
+// check returns the conflicts between an assignment on a line and
+// the assignments recorded so far
+func (t *assignmentTracker) check(line int, keys []interface{}, value Token) []AssignmentConflict {
+	var out []AssignmentConflict
+	parent := t.root
+	for i, kind := range assignmentKinds(keys, value) {
+		prev, exists := parent.children[keys[i]]
+		if !exists {
+			break
+		}
+		if prev.kind != kind {
+			out = append(out, AssignmentConflict{
+				Path:  gronPath(keys[:i+1]),
+				First: prev.line,
+				Last:  line,
+
+				replacesContainer: prev.kind == "{}" || prev.kind == "[]",
+			})
+		}
+		parent = prev
+	}
+	return out
+}
+
+// record records an assignment on a line. A path that's given a new
+// kind of value forgets everything recorded beneath it
+func (t *assignmentTracker) record(line int, keys []interface{}, value Token) {
+	parent := t.root
+	for i, kind := range assignmentKinds(keys, value) {
+		if parent.children == nil {
+			parent.children = make(map[interface{}]*assignment)
+		}
+		a, exists := parent.children[keys[i]]
+		switch {
+		case !exists:
+			a = &assignment{line: line, kind: kind}
+			parent.children[keys[i]] = a
+		case a.kind != kind:
+			*a = assignment{line: line, kind: kind}
+		}
+		parent = a
+	}
+}
+
+// forget removes the records for a deleted path and everything beneath
+// it. Deleting an array element moves the elements after it, so the
+// records for the whole array are removed
+func (t *assignmentTracker) forget(keys []interface{}) {
+	if _, ok := keys[len(keys)-1].(int); ok && len(keys) > 1 {
+		keys = keys[:len(keys)-1]
+	}
+
+	parent := t.root
+	for _, k := range keys[:len(keys)-1] {
+		a, exists := parent.children[k]
+		if !exists {
+			return
+		}
+		parent = a
+	}
+	delete(parent.children, keys[len(keys)-1])
+}
+
+// gronPath returns the gron form of a path; e.g. json.a[0]
+func gronPath(keys []interface{}) string {
+	s := Statement{{fmt.Sprintf("%v", keys[0]), TypBare}}
+	for _, k := range keys[1:] {
+		switch kk := k.(type) {
+		case int:
+			s = s.withNumericKey(kk)
+		case string:
+			s = s.withKey(kk)
+		}
+	}
+	return s.String()
+}
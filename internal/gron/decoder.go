@@ -1,11 +1,11 @@
 package gron
 
 import (
+	"fmt"
 	"io"
+	"strings"
 
 	json "github.com/virtuald/go-ordered-json"
-
-	"gopkg.in/yaml.v3"
 )
 
 // an ActionFn represents a main action of the program, it accepts
@@ -17,10 +17,85 @@ type Decoder interface {
 	Decode(interface{}) error
 }
 
-func MakeDecoder(r io.Reader, asYaml bool, sort bool) Decoder {
-	if asYaml {
-		return yaml.NewDecoder(r)
-	} else {
+// An InputFormat is a syntax that a document can be read from
+type InputFormat int
+
+const (
+	// InputJSON is JSON
+	InputJSON InputFormat = iota
+
+	// InputYAML is YAML
+	InputYAML
+
+	// InputCSV is a table of comma separated values with a header row,
+	// read as an array with an object for each row
+	InputCSV
+
+	// InputTSV is a table of tab separated values with a header row,
+	// read as an array with an object for each row
+	InputTSV
+
+	// InputXML is XML, read as an object keyed by the name of the
+	// document element
+	InputXML
+
+	// InputJSON5 is JSON5, which also covers JSON with comments and
+	// trailing commas (JSONC)
+	InputJSON5
+)
+
+// InputFormatFromString returns the InputFormat for a name as
+// accepted on the command line
+func InputFormatFromString(name string) (InputFormat, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return InputJSON, nil
+	case "yaml", "yml":
+		return InputYAML, nil
+	case "csv":
+		return InputCSV, nil
+	case "tsv":
+		return InputTSV, nil
+	case "xml":
+		return InputXML, nil
+	case "json5", "jsonc":
+		return InputJSON5, nil
+	default:
+		return InputJSON, fmt.Errorf("unknown input format `%s`", name)
+	}
+}
+
+// inputFormat returns the InputFormat for YAML input if inYaml
+// is set, or JSON input if not
+func inputFormat(inYaml bool) InputFormat {
+	if inYaml {
+		return InputYAML
+	}
+	return InputJSON
+}
+
+// MakeDecoder returns a Decoder for input in a format. Objects keep the
+// order of their keys unless the output is going to be sorted anyway,
+// which is only an optimisation for JSON
+func MakeDecoder(r io.Reader, format InputFormat, sort bool) Decoder {
+	return makeDecoder(r, format, sort, GronOptions{})
+}
+
+// makeDecoder returns a Decoder the same way as MakeDecoder, reading
+// CSV and TSV input with the table options of opts
+func makeDecoder(r io.Reader, format InputFormat, sort bool, opts GronOptions) Decoder {
+	switch format {
+	case InputYAML:
+		return newYAMLDecoder(r)
+	case InputCSV:
+		return newTableDecoder(r, ',', opts.TableTypes, opts.TablePaths)
+	case InputTSV:
+		return newTableDecoder(r, '\t', opts.TableTypes, opts.TablePaths)
+	case InputXML:
+		return newXMLDecoder(r)
+	case InputJSON5:
+		return newJSON5Decoder(r, sort)
+	default:
 		d := json.NewDecoder(r)
 		if !sort {
 			d.UseOrderedObject()
@@ -0,0 +1,181 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+// A DiffOp identifies how a statement differs between two documents
+type DiffOp int
+
+const (
+	// DiffAdded is a statement that only exists in the second document
+	DiffAdded DiffOp = iota
+
+	// DiffRemoved is a statement that only exists in the first document
+	DiffRemoved
+
+	// DiffChanged is a statement whose path exists in both
+	// documents but with a different value
+	DiffChanged
+)
+
+// marker returns the character used to mark the operation in diff output
+func (op DiffOp) marker() string {
+	switch op {
+	case DiffAdded:
+		return "+"
+	case DiffRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+var (
+	addColor    = color.New(color.FgGreen)
+	removeColor = color.New(color.FgRed)
+	changeColor = color.New(color.FgYellow)
+)
+
+// markerColor returns the color used for the operation's marker
+func (op DiffOp) markerColor() *color.Color {
+	switch op {
+	case DiffAdded:
+		return addColor
+	case DiffRemoved:
+		return removeColor
+	default:
+		return changeColor
+	}
+}
+
+// A Difference is a single statement that differs between two documents.
+// For added statements Old is nil, for removed statements New is nil
+type Difference struct {
+	Op  DiffOp
+	Old Statement
+	New Statement
+}
+
+// statement returns the most recent version of the differing statement
+func (d Difference) statement() Statement {
+	if d.New != nil {
+		return d.New
+	}
+	return d.Old
+}
+
+// Differences is a list of Differences, sortable with the same
+// natural ordering as Statements
+type Differences []Difference
+
+// Len returns the number of differences for sort.Sort
+func (ds Differences) Len() int {
+	return len(ds)
+}
+
+// Swap swaps two differences for sort.Sort
+func (ds Differences) Swap(i, j int) {
+	ds[i], ds[j] = ds[j], ds[i]
+}
+
+// Less compares the paths of two differences for sort.Sort
+func (ds Differences) Less(i, j int) bool {
+	return Statements{ds[i].statement(), ds[j].statement()}.Less(0, 1)
+}
+
+// DiffStatements compares two lists of statements by path and
+// returns the statements that were added, removed or changed,
+// sorted by path. Statements that are not assignments are ignored
+func DiffStatements(a, b Statements) Differences {
+	index := func(ss Statements) map[string]Statement {
+		m := make(map[string]Statement, len(ss))
+		for _, s := range ss {
+			path, _, ok := s.splitAssignment()
+			if !ok {
+				continue
+			}
+			m[path.String()] = s
+		}
+		return m
+	}
+	aIndex := index(a)
+	bIndex := index(b)
+
+	ds := make(Differences, 0)
+	for path, aS := range aIndex {
+		bS, exists := bIndex[path]
+		if !exists {
+			ds = append(ds, Difference{Op: DiffRemoved, Old: aS})
+			continue
+		}
+		_, aValue, _ := aS.splitAssignment()
+		_, bValue, _ := bS.splitAssignment()
+		if aValue != bValue {
+			ds = append(ds, Difference{Op: DiffChanged, Old: aS, New: bS})
+		}
+	}
+	for path, bS := range bIndex {
+		if _, exists := aIndex[path]; !exists {
+			ds = append(ds, Difference{Op: DiffAdded, New: bS})
+		}
+	}
+
+	sort.Sort(ds)
+	return ds
+}
+
+// String returns the diff output form of a difference: the statement
+// prefixed with its marker, and for changed statements followed by a
+// comment holding the old value; e.g. `~ json.baz = 3; // was 4`
+func (d Difference) String() string {
+	out := d.Op.marker() + " " + d.statement().String()
+	if d.Op == DiffChanged {
+		_, old, _ := d.Old.splitAssignment()
+		out += " // was " + old.Text
+	}
+	return out
+}
+
+// colorString returns the diff output form of a difference with ASCII color codes
+func (d Difference) colorString() string {
+	out := d.Op.markerColor().Sprint(d.Op.marker()) + " " + d.statement().colorString()
+	if d.Op == DiffChanged {
+		_, old, _ := d.Old.splitAssignment()
+		out += " // was " + old.formatColor()
+	}
+	return out
+}
+
+// Diff grons two documents, read in the formats given for each, and
+// writes the differences between them. It returns exitDiffer if there
+// are any differences, making it suitable for use in assertions the
+// same way as diff(1)
+func Diff(a io.Reader, b io.Reader, w io.Writer, aFormat InputFormat, bFormat InputFormat, colorize bool) (int, error) {
+	aSS, err := StatementsFromJSON(MakeDecoder(a, aFormat, true), Statement{{"json", TypBare}})
+	if err != nil {
+		return exitFormStatements, fmt.Errorf("failed to form statements from first input: %s", err)
+	}
+	bSS, err := StatementsFromJSON(MakeDecoder(b, bFormat, true), Statement{{"json", TypBare}})
+	if err != nil {
+		return exitFormStatements, fmt.Errorf("failed to form statements from second input: %s", err)
+	}
+
+	ds := DiffStatements(aSS, bSS)
+	for _, d := range ds {
+		if colorize {
+			fmt.Fprintln(w, d.colorString())
+		} else {
+			fmt.Fprintln(w, d)
+		}
+	}
+
+	if len(ds) > 0 {
+		return exitDiffer, nil
+	}
+	return exitOK, nil
+}
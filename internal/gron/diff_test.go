@@ -0,0 +1,103 @@
+package gron
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDiffStatements(t *testing.T) {
+	a := statementsFromStringSlice([]string{
+		`json = {};`,
+		`json.likes = [];`,
+		`json.likes[0] = "code";`,
+		`json.likes[2] = "meat";`,
+		`json.likes[10] = "tea";`,
+		`json.name = "Tom";`,
+		`json.old = true;`,
+	})
+	b := statementsFromStringSlice([]string{
+		`json = {};`,
+		`json.likes = [];`,
+		`json.likes[0] = "code";`,
+		`json.likes[2] = "cheese";`,
+		`json.likes[10] = "tea";`,
+		`json.likes[11] = "coffee";`,
+		`json.name = "Tom";`,
+	})
+
+	want := []string{
+		`~ json.likes[2] = "cheese"; // was "meat"`,
+		`+ json.likes[11] = "coffee";`,
+		`- json.old = true;`,
+	}
+
+	have := DiffStatements(a, b)
+	if len(have) != len(want) {
+		t.Fatalf("want %d differences; have %d: %v", len(want), len(have), have)
+	}
+	for i := range want {
+		if have[i].String() != want[i] {
+			t.Errorf("want `%s` at index %d; have `%s`", want[i], i, have[i])
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		aFile string
+		bFile string
+		code  int
+		want  string
+	}{
+		{"testdata/two.json", "testdata/two.json", exitOK, ""},
+		{"testdata/two.json", "testdata/two-b.json", exitDiffer,
+			"~ json.contact.email = \"contact@tomnomnom.com\"; // was \"mail@tomnomnom.com\"\n"},
+	}
+
+	for _, c := range cases {
+		a, err := os.Open(c.aFile)
+		if err != nil {
+			t.Fatalf("failed to open input file: %s", err)
+		}
+		b, err := os.Open(c.bFile)
+		if err != nil {
+			t.Fatalf("failed to open input file: %s", err)
+		}
+
+		out := &bytes.Buffer{}
+		code, err := Diff(a, b, out, InputJSON, InputJSON, false)
+
+		if code != c.code {
+			t.Errorf("want exit code %d; have %d", c.code, code)
+		}
+		if err != nil {
+			t.Errorf("want nil error; have %s", err)
+		}
+		if out.String() != c.want {
+			t.Logf("want: %s", c.want)
+			t.Logf("have: %s", out.String())
+			t.Errorf("diff of %s and %s does not match", c.aFile, c.bFile)
+		}
+	}
+}
+
+func TestDiffFormats(t *testing.T) {
+	a := strings.NewReader("{\n  // who\n  root: {name: 'Tom', likes: ['code',]},\n}\n")
+	b := strings.NewReader("<root><name>Tom</name><likes>tea</likes></root>")
+	want := "~ json.root.likes = \"tea\"; // was []\n" +
+		"- json.root.likes[0] = \"code\";\n"
+
+	out := &bytes.Buffer{}
+	code, err := Diff(a, b, out, InputJSON5, InputXML, false)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if code != exitDiffer {
+		t.Errorf("want exit code %d; have %d", exitDiffer, code)
+	}
+	if out.String() != want {
+		t.Errorf("want:\n%s\nhave:\n%s", want, out.String())
+	}
+}
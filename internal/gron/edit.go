@@ -0,0 +1,207 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// parsePath lexes a gron path such as json.foo[0] and returns its
+// keys, not including the leading bare word
+func parsePath(path string) ([]interface{}, error) {
+	s := StatementFromString(strings.TrimSpace(path) + " = null;")
+	p, value, ok := s.splitAssignment()
+	if !ok || value.Typ != TypNull {
+		return nil, fmt.Errorf("invalid path `%s`", path)
+	}
+	keys, err := p.Path()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path `%s`", path)
+	}
+	return keys, nil
+}
+
+// pathSkeleton returns the bare structure of a path: the objects and
+// arrays that hold a null at the end of it, as ungron would make them
+func pathSkeleton(keys []interface{}) interface{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	val := pathSkeleton(keys[1:])
+	switch k := keys[0].(type) {
+	case int:
+		out := make([]interface{}, k+1)
+		out[k] = val
+		return out
+	default:
+		return json.OrderedObject{{Key: fmt.Sprintf("%v", k), Value: val}}
+	}
+}
+
+// parseValue turns the text of a value into a value. Any JSON value
+// can be given; text that isn't valid JSON is used as a string
+func parseValue(text string, rawString bool) interface{} {
+	if rawString {
+		return text
+	}
+
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(text))
+	d.UseOrderedObject()
+	d.UseNumber()
+	err := d.Decode(&v)
+	if err != nil || d.More() {
+		return text
+	}
+	return v
+}
+
+// SetPath sets the value at a gron path in a document, creating any
+// objects and arrays along the way the same way that ungron does.
+// Array indexes that would leave more than DefaultMaxIndex missing
+// elements are an error, as for checkIndex
+func SetPath(doc interface{}, path string, value interface{}) (interface{}, error) {
+	keys, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return value, nil
+	}
+	if err := checkPathIndexes(doc, keys, DefaultMaxIndex); err != nil {
+		return nil, errors.Wrapf(err, "cannot set `%s`", path)
+	}
+
+	// If the path doesn't exist yet, merge in the bare structure of
+	// the path so that it does, then replace the final value
+	if _, exists := valueAt(doc, keys); !exists {
+		skeleton := pathSkeleton(keys)
+		if doc == nil {
+			doc = skeleton
+		} else {
+			doc, err = recursiveMerge(doc, skeleton)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot set `%s`", path)
+			}
+		}
+	}
+
+	tokens := make([]string, len(keys))
+	for i, k := range keys {
+		tokens[i] = fmt.Sprintf("%v", k)
+	}
+	return patchReplace(doc, tokens, value)
+}
+
+// DeletePath removes the value at a gron path from a document.
+// Paths that don't exist are ignored
+func DeletePath(doc interface{}, path string) (interface{}, error) {
+	keys, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("cannot delete the whole document")
+	}
+	return deletePath(doc, keys), nil
+}
+
+// writeDocument writes a value as JSON or YAML
+func writeDocument(w io.Writer, v interface{}, asYaml bool) error {
+	if asYaml {
+		return encodeYAML(w, v)
+	}
+	return writeJSON(w, v, false, UngronOptions{})
+}
+
+// editDocument decodes a document, applies an edit to it and writes it
+// back out in the same format. YAML documents keep their comments,
+// styles and tags wherever they weren't changed
+func editDocument(r io.Reader, w io.Writer, inYaml bool, edit func(interface{}) (interface{}, error)) (int, error) {
+	var doc interface{}
+	var yd *yamlDecoder
+	var d Decoder
+	if inYaml {
+		yd = newYAMLDecoder(r)
+		d = yd
+	} else {
+		d = MakeDecoder(r, InputJSON, false)
+	}
+	err := d.Decode(&doc)
+	if err != nil && err != io.EOF {
+		return exitReadInput, fmt.Errorf("failed to decode input: %s", err)
+	}
+
+	edited, err := edit(deepCopy(doc))
+	if err != nil {
+		return exitParseStatements, err
+	}
+
+	if inYaml {
+		err = writeYAMLEdit(w, yd.doc, doc, edited, nil)
+	} else {
+		err = writeDocument(w, edited, false)
+	}
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write document")
+	}
+	return exitOK, nil
+}
+
+// Set reads a JSON or YAML document, sets the value at a gron path and
+// writes the document back out in the same format. Values are parsed
+// as JSON unless rawString is set
+func Set(r io.Reader, w io.Writer, path string, value string, inYaml bool, rawString bool) (int, error) {
+	return editDocument(r, w, inYaml, func(doc interface{}) (interface{}, error) {
+		return SetPath(doc, path, parseValue(value, rawString))
+	})
+}
+
+// Del reads a JSON or YAML document, removes the value at a gron path
+// and writes the document back out in the same format
+func Del(r io.Reader, w io.Writer, path string, inYaml bool) (int, error) {
+	return editDocument(r, w, inYaml, func(doc interface{}) (interface{}, error) {
+		return DeletePath(doc, path)
+	})
+}
+
+// UngronDocument reads gron statements and writes the document they make
+// up as JSON or YAML. Problems with the statements are returned as a
+// *StatementError so that the offending line can be found. If original
+// isn't nil, it's the YAML document that the statements were made from,
+// and only the values that were changed are written differently, so
+// that its comments and styles are kept
+func UngronDocument(r io.Reader, w io.Writer, asYaml bool, original io.Reader) (int, error) {
+	ss, code, err := readStatements(r, StatementFromStringMaker)
+	if err != nil {
+		return code, err
+	}
+
+	merged, err := ss.ToInterface()
+	if err != nil {
+		return exitParseStatements, err
+	}
+
+	switch {
+	case asYaml && original != nil:
+		var before interface{}
+		d := newYAMLDecoder(original)
+		err = d.Decode(&before)
+		if err != nil && err != io.EOF {
+			return exitReadInput, fmt.Errorf("failed to decode original document: %s", err)
+		}
+		err = writeYAMLEdit(w, d.doc, before, unwrapRoot(merged), ss.yamlTags())
+	case asYaml:
+		err = encodeYAMLWithTags(w, unwrapRoot(merged), ss.yamlTags())
+	default:
+		err = writeDocument(w, unwrapRoot(merged), false)
+	}
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write document")
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,210 @@
+package gron
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+func TestSetPath(t *testing.T) {
+	cases := []struct {
+		doc   string
+		path  string
+		value string
+		want  string
+	}{
+		{`{"a":1}`, `json.a`, `2`, `{"a":2}`},
+		{`{"a":1}`, `json.b.c`, `true`, `{"a":1,"b":{"c":true}}`},
+		{`{"a":[1,2]}`, `json.a[1]`, `{"x":null}`, `{"a":[1,{"x":null}]}`},
+		{`{"a":[1]}`, `json.a[2]`, `"x"`, `{"a":[1,null,"x"]}`},
+		{`{"a":{"b":{"c":1}}}`, `json.a.b`, `[]`, `{"a":{"b":[]}}`},
+		{`{"a":1}`, `json["a b"]`, `not json`, `{"a":1,"a b":"not json"}`},
+		{`{"a":1}`, `json`, `[1]`, `[1]`},
+	}
+
+	for _, c := range cases {
+		have, err := SetPath(decodeOrdered(t, c.doc), c.path, parseValue(c.value, false))
+		if err != nil {
+			t.Fatalf("want nil error setting %s in %s; have %s", c.path, c.doc, err)
+		}
+
+		j, err := encodeJSON(have, UngronOptions{Compact: true})
+		if err != nil {
+			t.Fatalf("failed to encode JSON: %s", err)
+		}
+		if string(j) != c.want {
+			t.Errorf("setting %s to %s in %s; want %s have %s", c.path, c.value, c.doc, c.want, j)
+		}
+	}
+}
+
+func TestSetPathInvalid(t *testing.T) {
+	cases := []struct {
+		doc  string
+		path string
+	}{
+		{`{"a":1}`, `json.a[`},
+		{`{"a":1}`, `not a path`},
+		{`{"a":[1]}`, `json.a.b`},
+		{`{"a":[1]}`, `json.a[999999999]`},
+	}
+
+	for _, c := range cases {
+		_, err := SetPath(decodeOrdered(t, c.doc), c.path, 1)
+		if err == nil {
+			t.Errorf("want non-nil error setting %s in %s; have nil", c.path, c.doc)
+		}
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	cases := []struct {
+		doc  string
+		path string
+		want string
+	}{
+		{`{"a":1,"b":2}`, `json.a`, `{"b":2}`},
+		{`{"a":{"tls":[1,2,3]}}`, `json.a.tls[0]`, `{"a":{"tls":[2,3]}}`},
+		{`{"a":1}`, `json.missing.key`, `{"a":1}`},
+		{`{"a b":1}`, `json["a b"]`, `{}`},
+	}
+
+	for _, c := range cases {
+		have, err := DeletePath(decodeOrdered(t, c.doc), c.path)
+		if err != nil {
+			t.Fatalf("want nil error deleting %s from %s; have %s", c.path, c.doc, err)
+		}
+
+		j, err := encodeJSON(have, UngronOptions{Compact: true})
+		if err != nil {
+			t.Fatalf("failed to encode JSON: %s", err)
+		}
+		if string(j) != c.want {
+			t.Errorf("deleting %s from %s; want %s have %s", c.path, c.doc, c.want, j)
+		}
+	}
+}
+
+func TestSetPathMaxIndex(t *testing.T) {
+	_, err := SetPath(decodeOrdered(t, `{"a":[1]}`), `json.a[999999999]`, 2)
+	if err == nil {
+		t.Errorf("want non-nil error for an index past the limit; have nil")
+	}
+
+	long := make([]interface{}, DefaultMaxIndex+5)
+	doc := json.OrderedObject{{Key: "a", Value: long}}
+	v, err := SetPath(doc, fmt.Sprintf("json.a[%d]", len(long)), 2)
+	if err != nil {
+		t.Fatalf("want nil error for an index that extends the array by one; have %s", err)
+	}
+	if a, _ := objectGet(v, "a"); len(a.([]interface{})) != len(long)+1 {
+		t.Errorf("want %d elements; have %d", len(long)+1, len(a.([]interface{})))
+	}
+}
+
+func TestSetYAML(t *testing.T) {
+	in := `image:
+  repository: nginx
+  tag: "1.0"
+replicas: 2
+ingress:
+  tls:
+    - a
+    - b
+`
+	want := `image:
+  repository: nginx
+  tag: "1.25"
+replicas: 2
+ingress:
+  tls:
+    - a
+    - b
+`
+
+	out := &bytes.Buffer{}
+	code, err := Set(strings.NewReader(in), out, "json.image.tag", "1.25", true, true)
+	if code != exitOK {
+		t.Errorf("want exitOK; have %d", code)
+	}
+	if err != nil {
+		t.Errorf("want nil error; have %s", err)
+	}
+	if out.String() != want {
+		t.Logf("want: %s", want)
+		t.Logf("have: %s", out.String())
+		t.Errorf("YAML written by set does not match")
+	}
+}
+
+func TestEditYAMLKeepsComments(t *testing.T) {
+	in := `# top comment
+image:
+  repository: nginx
+  tag: "1.1" # pinned
+when: 2001-12-14
+base: &base
+  retries: 3
+job:
+  <<: *base
+  name: build
+`
+
+	cases := []struct {
+		edit func(r *strings.Reader, w *bytes.Buffer) (int, error)
+		want string
+	}{
+		{
+			func(r *strings.Reader, w *bytes.Buffer) (int, error) {
+				return Set(r, w, "json.image.tag", `"1.2"`, true, false)
+			},
+			strings.Replace(in, `"1.1"`, `"1.2"`, 1),
+		},
+		{
+			func(r *strings.Reader, w *bytes.Buffer) (int, error) {
+				return Set(r, w, "json.job.retries", "5", true, false)
+			},
+			in + "  retries: 5\n",
+		},
+		{
+			func(r *strings.Reader, w *bytes.Buffer) (int, error) {
+				return Del(r, w, "json.image.repository", true)
+			},
+			strings.Replace(in, "  repository: nginx\n", "", 1),
+		},
+		{
+			func(r *strings.Reader, w *bytes.Buffer) (int, error) {
+				gronned := &bytes.Buffer{}
+				_, err := Gron(strings.NewReader(in), gronned, StatementToString, InputYAML, false, false, GronOptions{YAMLTags: true})
+				if err != nil {
+					return exitFormStatements, err
+				}
+				edited := strings.Replace(gronned.String(), `"build"`, `"test"`, 1)
+				return UngronDocument(strings.NewReader(edited), w, true, r)
+			},
+			strings.Replace(in, "name: build", "name: test", 1),
+		},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := c.edit(strings.NewReader(in), out)
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to edit YAML: %d, %s", code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%s\nhave:\n%s", c.want, out.String())
+		}
+	}
+}
+
+func TestEditYAMLMergedKey(t *testing.T) {
+	in := "base: &base\n  retries: 3\njob:\n  <<: *base\n"
+	code, err := Del(strings.NewReader(in), &bytes.Buffer{}, "json.job.retries", true)
+	if code == exitOK || err == nil {
+		t.Errorf("want error deleting a key that comes from a merge key; have none")
+	}
+}
@@ -0,0 +1,98 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+// embeddedJSONComment marks the statements for values that were
+// decoded from JSON held in a string.
+// E.g:
+//
+//	json.body = {}; // embedded JSON
+//	json.body.id = 1;
+const embeddedJSONComment = "embedded JSON"
+
+// decodeEmbeddedJSON decodes a string that holds a JSON object or array
+// and nothing else. Other strings, including those holding JSON
+// scalars such as "12" or "true", are left alone
+func decodeEmbeddedJSON(s string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	d := json.NewDecoder(strings.NewReader(trimmed))
+	d.UseOrderedObject()
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, false
+	}
+	var extra interface{}
+	if err := d.Decode(&extra); err != io.EOF {
+		return nil, false
+	}
+	return v, true
+}
+
+// embeddedPaths returns the paths of the statements marked as holding
+// embedded JSON, deepest first so that embedded values inside other
+// embedded values are encoded before their parents
+func (ss Statements) embeddedPaths() [][]interface{} {
+	var out [][]interface{}
+	for _, s := range ss {
+		marked := false
+		for _, part := range s.commentParts() {
+			marked = marked || part == embeddedJSONComment
+		}
+		if !marked {
+			continue
+		}
+
+		path, _, ok := s.splitAssignment()
+		if !ok {
+			continue
+		}
+		keys, err := path.Path()
+		if err != nil {
+			continue
+		}
+		out = append(out, keys)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i]) > len(out[j])
+	})
+	return out
+}
+
+// encodeEmbedded replaces the values at the paths marked as holding
+// embedded JSON with strings holding their compact JSON encoding
+func (ss Statements) encodeEmbedded(v interface{}) (interface{}, error) {
+	for _, keys := range ss.embeddedPaths() {
+		embedded, ok := valueAt(v, keys)
+		if !ok {
+			continue
+		}
+		j, err := encodeJSON(embedded, UngronOptions{Compact: true})
+		if err != nil {
+			return nil, err
+		}
+
+		tokens := make([]string, len(keys))
+		for i, k := range keys {
+			tokens[i] = fmt.Sprintf("%v", k)
+		}
+		v, err = patchReplace(v, tokens, string(j))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,62 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeEmbeddedJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want bool
+	}{
+		{`{"a":1}`, true},
+		{` [1, 2] `, true},
+		{`{}`, true},
+		{`12`, false},
+		{`"quoted"`, false},
+		{`true`, false},
+		{`[not json`, false},
+		{`{"a":1} {"b":2}`, false},
+		{`{"a":1} trailing`, false},
+	}
+
+	for _, c := range cases {
+		_, have := decodeEmbeddedJSON(c.in)
+		if have != c.want {
+			t.Errorf("want %t for `%s`; have %t", c.want, c.in, have)
+		}
+	}
+}
+
+func TestGronEmbeddedJSON(t *testing.T) {
+	in := `{"message":"{\"id\":1,\"inner\":\"[true]\"}","plain":"[x"}`
+	want := []string{
+		`json = {};`,
+		`json.message = {}; // embedded JSON`,
+		`json.message.id = 1;`,
+		`json.message.inner = []; // embedded JSON`,
+		`json.message.inner[0] = true;`,
+		`json.plain = "[x";`,
+	}
+
+	out := &bytes.Buffer{}
+	_, err := Gron(strings.NewReader(in), out, StatementToString, InputJSON, false, false, GronOptions{EmbeddedJSON: true})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	have := strings.TrimSpace(out.String())
+	if have != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\nhave:\n%s", strings.Join(want, "\n"), have)
+	}
+
+	ungronned := &bytes.Buffer{}
+	_, err = Ungron(out, ungronned, false, false, UngronOptions{Compact: true})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if strings.TrimSpace(ungronned.String()) != in {
+		t.Errorf("want %s; have %s", in, ungronned.String())
+	}
+}
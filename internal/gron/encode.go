@@ -0,0 +1,154 @@
+package gron
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+// defaultIndent is the per-level indentation used when none is given
+const defaultIndent = "  "
+
+// UngronOptions controls how ungronned values are written out
+type UngronOptions struct {
+	// Compact writes the JSON on a single line with no indentation
+	Compact bool
+
+	// Indent is the string used for each level of indentation;
+	// it defaults to two spaces and is ignored if Compact is set
+	Indent string
+
+	// SortKeys sorts the keys of every object instead of keeping
+	// the order in which they first appeared
+	SortKeys bool
+
+	// ASCII escapes every non-ASCII rune as a \uXXXX sequence
+	ASCII bool
+
+	// Lines writes each element of a top level array as a separate
+	// compact JSON document, one per line (JSON Lines). Elements that
+	// no statement assigns, such as those filtered out by grep, are
+	// skipped rather than written as null
+	Lines bool
+
+	// Conflicts decides what happens to a statement that assigns a
+	// different value to a path than an earlier statement, in the same
+	// way as for merging documents
+	Conflicts ConflictPolicy
+
+	// Warnings is written a warning for each statement that conflicts
+	// with an earlier one if it isn't nil
+	Warnings io.Writer
+
+	// Sparse decides what happens to arrays with missing elements
+	Sparse SparseMode
+
+	// MaxIndex is the largest number of missing elements filled with
+	// null to reach an array index once sparse arrays have been
+	// handled; zero means DefaultMaxIndex and a negative value no limit
+	MaxIndex int
+
+	// YAML writes YAML instead of JSON, restoring the tags given
+	// in comments; e.g. json.t = "2001-12-14"; // !!timestamp
+	YAML bool
+
+	// XML writes XML instead of JSON, with the keys starting with @
+	// as attributes and #text as the text of the element
+	XML bool
+}
+
+// indent returns the indentation string to use for the options
+func (o UngronOptions) indent() string {
+	if o.Compact {
+		return ""
+	}
+	if o.Indent == "" {
+		return defaultIndent
+	}
+	return o.Indent
+}
+
+// encodeJSON marshals a value into JSON according to the options.
+// The returned JSON never has a trailing newline
+func encodeJSON(v interface{}, opts UngronOptions) ([]byte, error) {
+	if opts.SortKeys {
+		v = sortKeys(v)
+	}
+
+	out := &bytes.Buffer{}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", opts.indent())
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	j := bytes.TrimRight(out.Bytes(), "\n")
+
+	if opts.ASCII {
+		j = escapeNonASCII(j)
+	}
+	return j, nil
+}
+
+// sortKeys returns a copy of v where the members of every
+// ordered object are sorted by key
+func sortKeys(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		out := make(json.OrderedObject, len(vv))
+		for i, m := range vv {
+			out[i] = json.Member{Key: m.Key, Value: sortKeys(m.Value)}
+		}
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].Key < out[j].Key
+		})
+		return out
+
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(vv))
+		for k, sub := range vv {
+			out[k] = sortKeys(sub)
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(vv))
+		for i, sub := range vv {
+			out[i] = sortKeys(sub)
+		}
+		return out
+
+	default:
+		return v
+	}
+}
+
+// escapeNonASCII replaces every non-ASCII rune in some encoded JSON
+// with its \uXXXX escape sequence. Runes outside of the Basic
+// Multilingual Plane are written as UTF-16 surrogate pairs. Outside
+// of strings valid JSON is pure ASCII, so only strings are affected
+func escapeNonASCII(j []byte) []byte {
+	out := &bytes.Buffer{}
+	out.Grow(len(j))
+
+	for len(j) > 0 {
+		r, w := utf8.DecodeRune(j)
+		switch {
+		case r < utf8.RuneSelf:
+			_ = out.WriteByte(j[0])
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			_, _ = fmt.Fprintf(out, `\u%04x\u%04x`, r1, r2)
+		default:
+			_, _ = fmt.Fprintf(out, `\u%04x`, r)
+		}
+		j = j[w:]
+	}
+	return out.Bytes()
+}
@@ -17,14 +17,63 @@ const (
 	exitFetchURL
 	exitParseStatements
 	exitJSONEncode
+	exitConflict
+	exitInvalid
 )
 
+// exitDiffer is returned when compared documents differ. It matches
+// the exit code of diff(1) and so shares its value with exitOpenFile
+const exitDiffer = 1
+
+// GronOptions controls how values are turned into statements
+type GronOptions struct {
+	// YAMLTags adds a comment holding the tag of each YAML value
+	// whose tag isn't implied by its value; e.g. // !!timestamp
+	YAMLTags bool
+
+	// EmbeddedJSON decodes string values that hold a JSON object or
+	// array, marking them with a comment so that ungron can encode
+	// them back into strings; e.g. // embedded JSON
+	EmbeddedJSON bool
+
+	// Redact replaces the scalar values that it redacts with
+	// RedactedValue, keeping the statements for objects and arrays
+	Redact *Redactor
+
+	// MaxArray is the number of elements of each array that are
+	// written, with a comment saying how many were left out; zero
+	// means every element
+	MaxArray int
+
+	// MaxStringLength is the number of runes of each string that are
+	// written, with an ellipsis marking strings that were cut short;
+	// zero means the whole string
+	MaxStringLength int
+
+	// TableTypes reads the cells of CSV and TSV input that hold a
+	// number, boolean or other JSON value as that value, rather than
+	// as a string
+	TableTypes bool
+
+	// TablePaths reads the headers of CSV and TSV input as paths to
+	// nested values, such as address.city, rather than as object keys
+	TablePaths bool
+}
+
 // Gron is the default action. Given JSON as the input it returns a list
 // of assignment statements. Possible options are optNoSort and optMonochrome
-func Gron(r io.Reader, w io.Writer, conv StatementConv, inYaml bool, sortOutput bool, outJson bool) (int, error) {
+func Gron(
+	r io.Reader,
+	w io.Writer,
+	conv StatementConv,
+	format InputFormat,
+	sortOutput bool,
+	outJson bool,
+	opts GronOptions,
+) (int, error) {
 	var err error
 
-	ss, err := StatementsFromJSON(MakeDecoder(r, inYaml, sortOutput), Statement{{"json", TypBare}})
+	ss, err := statementsFromDecoder(makeDecoder(r, format, sortOutput, opts), Statement{{"json", TypBare}}, opts)
 	if err != nil {
 		goto out
 	}
@@ -59,9 +108,10 @@ func GronStream(
 	r io.Reader,
 	w io.Writer,
 	conv StatementConv,
-	inYaml bool,
+	format InputFormat,
 	outSort bool,
 	outJson bool,
+	opts GronOptions,
 ) (int, error) {
 	var err error
 	errstr := "failed to form statements"
@@ -107,7 +157,7 @@ func GronStream(
 		line := bytes.NewBuffer(sc.Bytes())
 
 		var ss Statements
-		ss, err = StatementsFromJSON(MakeDecoder(line, inYaml, outSort), makePrefix(i))
+		ss, err = statementsFromDecoder(makeDecoder(line, format, outSort, opts), makePrefix(i), opts)
 		i++
 		if err != nil {
 			goto out
@@ -30,7 +30,7 @@ func TestGronUnsorted(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := Gron(in, out, StatementToString, false, false, false)
+		code, err := Gron(in, out, StatementToString, InputJSON, false, false, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -70,7 +70,7 @@ func TestGronSorted(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := Gron(in, out, StatementToString, false, true, false)
+		code, err := Gron(in, out, StatementToString, InputJSON, true, false, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -108,7 +108,7 @@ func TestGronStream(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := GronStream(in, out, StatementToString, false, true, false)
+		code, err := GronStream(in, out, StatementToString, InputJSON, true, false, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -145,7 +145,7 @@ func TestLargeGronStream(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := GronStream(in, out, StatementToString, false, true, false)
+		code, err := GronStream(in, out, StatementToString, InputJSON, true, false, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -186,7 +186,7 @@ func TestGronJ(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := Gron(in, out, StatementToString, false, c.sort, true)
+		code, err := Gron(in, out, StatementToString, InputJSON, c.sort, true, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -224,7 +224,7 @@ func TestGronStreamJ(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := GronStream(in, out, StatementToString, false, true, true)
+		code, err := GronStream(in, out, StatementToString, InputJSON, true, true, GronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -254,7 +254,7 @@ func BenchmarkBigJSON(b *testing.B) {
 			b.Fatalf("failed to rewind input: %s", err)
 		}
 
-		_, err := Gron(in, out, StatementToString, false, true, false)
+		_, err := Gron(in, out, StatementToString, InputJSON, true, false, GronOptions{})
 		if err != nil {
 			b.Fatalf("failed to gron: %s", err)
 		}
@@ -0,0 +1,396 @@
+package gron
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+// A json5Decoder reads JSON5, which also covers JSON with comments
+// (JSONC) such as VS Code settings and tsconfig.json. The input is
+// rewritten as JSON and read with the same decoder as JSON input so
+// that objects keep the order of their keys
+type json5Decoder struct {
+	r    io.Reader
+	sort bool
+	d    *json.Decoder
+}
+
+// newJSON5Decoder returns a json5Decoder for r. As with JSON input,
+// objects keep the order of their keys unless sort is set
+func newJSON5Decoder(r io.Reader, sort bool) *json5Decoder {
+	return &json5Decoder{r: r, sort: sort}
+}
+
+// Decode reads the next value into v. The whole input is read
+// and rewritten as JSON the first time that it's called
+func (d *json5Decoder) Decode(v interface{}) error {
+	if d.d == nil {
+		src, err := io.ReadAll(d.r)
+		if err != nil {
+			return err
+		}
+		j, err := json5ToJSON(src)
+		if err != nil {
+			return err
+		}
+		d.d = json.NewDecoder(bytes.NewReader(j))
+		if !d.sort {
+			d.d.UseOrderedObject()
+		}
+		d.d.UseNumber()
+	}
+	return d.d.Decode(v)
+}
+
+// json5ToJSON rewrites JSON5 as JSON: comments are removed, as are
+// trailing commas; unquoted keys and single quoted strings are double
+// quoted; and hexadecimal numbers, numbers with a leading + or a leading
+// or trailing decimal point are written as JSON numbers. Infinity and NaN
+// can't be written as JSON and are an error.
+// E.g:
+//
+//	{a: 0x10, 'b': .5,} -> {"a": 16, "b": 0.5}
+func json5ToJSON(src []byte) ([]byte, error) {
+	c := &json5Converter{src: []rune(string(src))}
+	err := c.convert()
+	if err != nil {
+		return nil, err
+	}
+	return c.out.Bytes(), nil
+}
+
+// A json5Converter rewrites JSON5 as JSON one token at a time
+type json5Converter struct {
+	src []rune
+	pos int
+	out bytes.Buffer
+}
+
+// errorf returns an error for the current position in the input
+func (c *json5Converter) errorf(format string, args ...interface{}) error {
+	line, col := 1, 1
+	for _, r := range c.src[:c.pos] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Errorf("invalid JSON5 at line %d, column %d: %s", line, col, fmt.Sprintf(format, args...))
+}
+
+// at returns the rune at an offset from the current position,
+// or zero at the end of the input
+func (c *json5Converter) at(offset int) rune {
+	if c.pos+offset >= len(c.src) {
+		return 0
+	}
+	return c.src[c.pos+offset]
+}
+
+// convert rewrites the whole input
+func (c *json5Converter) convert() error {
+	for c.pos < len(c.src) {
+		r := c.src[c.pos]
+		var err error
+		switch {
+		case r == '/':
+			err = c.comment()
+		case r == '"' || r == '\'':
+			var s string
+			s, err = c.str()
+			c.out.WriteString(quoteString(s))
+		case r == ',':
+			// Trailing commas are left out
+			c.pos++
+			if next := c.next(); next != '}' && next != ']' {
+				c.out.WriteByte(',')
+			}
+		case r == '+' || r == '-' || r == '.' || (r >= '0' && r <= '9'):
+			err = c.number()
+		case r == '$' || r == '_' || unicode.IsLetter(r):
+			err = c.word()
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			// JSON5 allows whitespace that JSON doesn't
+			if r == '\n' || r == '\r' || r == '\t' {
+				c.out.WriteRune(r)
+			} else {
+				c.out.WriteByte(' ')
+			}
+			c.pos++
+		default:
+			c.out.WriteRune(r)
+			c.pos++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next returns the next rune that isn't whitespace or part of a
+// comment without moving past anything, or zero at the end of the input
+func (c *json5Converter) next() rune {
+	i := c.pos
+	for i < len(c.src) {
+		r := c.src[i]
+		switch {
+		case unicode.IsSpace(r) || r == '\uFEFF':
+			i++
+		case r == '/' && i+1 < len(c.src) && c.src[i+1] == '/':
+			for i < len(c.src) && c.src[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(c.src) && c.src[i+1] == '*':
+			i += 2
+			for i+1 < len(c.src) && !(c.src[i] == '*' && c.src[i+1] == '/') {
+				i++
+			}
+			i += 2
+		default:
+			return r
+		}
+	}
+	return 0
+}
+
+// comment skips a comment, keeping any line breaks in it so that
+// the JSON decoder reports errors on the right line
+func (c *json5Converter) comment() error {
+	switch c.at(1) {
+	case '/':
+		for c.pos < len(c.src) && c.src[c.pos] != '\n' {
+			c.pos++
+		}
+		c.out.WriteByte(' ')
+		return nil
+	case '*':
+		c.pos += 2
+		for c.pos < len(c.src) {
+			if c.src[c.pos] == '*' && c.at(1) == '/' {
+				c.pos += 2
+				c.out.WriteByte(' ')
+				return nil
+			}
+			if c.src[c.pos] == '\n' {
+				c.out.WriteByte('\n')
+			}
+			c.pos++
+		}
+		return c.errorf("comment is not closed")
+	default:
+		return c.errorf("unexpected /")
+	}
+}
+
+// str reads a single or double quoted string, returning its value
+func (c *json5Converter) str() (string, error) {
+	quote := c.src[c.pos]
+	c.pos++
+
+	var out []rune
+	for c.pos < len(c.src) {
+		r := c.src[c.pos]
+		c.pos++
+		switch r {
+		case quote:
+			return string(out), nil
+		case '\n', '\r':
+			c.pos--
+			return "", c.errorf("string is not closed before the end of the line")
+		case '\\':
+			e, err := c.escape()
+			if err != nil {
+				return "", err
+			}
+			out = append(out, e...)
+		default:
+			out = append(out, r)
+		}
+	}
+	return "", c.errorf("string is not closed")
+}
+
+// escape reads the rest of an escape sequence in a string, after the
+// backslash, returning the runes that it stands for
+func (c *json5Converter) escape() ([]rune, error) {
+	if c.pos >= len(c.src) {
+		return nil, c.errorf("string is not closed")
+	}
+	r := c.src[c.pos]
+	c.pos++
+
+	switch r {
+	case 'b':
+		return []rune{'\b'}, nil
+	case 'f':
+		return []rune{'\f'}, nil
+	case 'n':
+		return []rune{'\n'}, nil
+	case 'r':
+		return []rune{'\r'}, nil
+	case 't':
+		return []rune{'\t'}, nil
+	case 'v':
+		return []rune{'\v'}, nil
+	case '0':
+		if d := c.at(0); d >= '0' && d <= '9' {
+			return nil, c.errorf("octal escapes are not allowed")
+		}
+		return []rune{0}, nil
+	case 'x':
+		return c.hexEscape(2)
+	case 'u':
+		out, err := c.hexEscape(4)
+		if err != nil {
+			return nil, err
+		}
+		// A surrogate pair is written as two escapes
+		if utf16.IsSurrogate(out[0]) && c.at(0) == '\\' && c.at(1) == 'u' {
+			c.pos += 2
+			low, err := c.hexEscape(4)
+			if err != nil {
+				return nil, err
+			}
+			if pair := utf16.DecodeRune(out[0], low[0]); pair != unicode.ReplacementChar {
+				return []rune{pair}, nil
+			}
+			return append(out, low...), nil
+		}
+		return out, nil
+	case '\r':
+		// Line continuations are left out
+		if c.at(0) == '\n' {
+			c.pos++
+		}
+		return nil, nil
+	case '\n', '\u2028', '\u2029':
+		return nil, nil
+	default:
+		// Any other character stands for itself; e.g. \' or \"
+		return []rune{r}, nil
+	}
+}
+
+// hexEscape reads an escape of n hexadecimal digits
+func (c *json5Converter) hexEscape(n int) ([]rune, error) {
+	if c.pos+n > len(c.src) {
+		return nil, c.errorf("escape is too short")
+	}
+	v, err := strconv.ParseUint(string(c.src[c.pos:c.pos+n]), 16, 32)
+	if err != nil {
+		return nil, c.errorf("invalid escape %s", string(c.src[c.pos-2:c.pos+n]))
+	}
+	c.pos += n
+	return []rune{rune(v)}, nil
+}
+
+// number reads a number and writes it as a JSON number
+func (c *json5Converter) number() error {
+	var sign string
+	switch c.src[c.pos] {
+	case '-':
+		sign = "-"
+		c.pos++
+	case '+':
+		c.pos++
+	}
+
+	rest := string(c.src[c.pos:])
+	for _, word := range []string{"Infinity", "NaN"} {
+		if strings.HasPrefix(rest, word) {
+			return c.errorf("%s can't be represented in JSON", word)
+		}
+	}
+
+	if c.at(0) == '0' && (c.at(1) == 'x' || c.at(1) == 'X') {
+		c.pos += 2
+		start := c.pos
+		for c.pos < len(c.src) && strings.ContainsRune("0123456789abcdefABCDEF", c.src[c.pos]) {
+			c.pos++
+		}
+		n, ok := new(big.Int).SetString(string(c.src[start:c.pos]), 16)
+		if !ok {
+			return c.errorf("invalid hexadecimal number")
+		}
+		c.out.WriteString(sign + n.String())
+		return nil
+	}
+
+	digits := func() string {
+		start := c.pos
+		for c.pos < len(c.src) && c.src[c.pos] >= '0' && c.src[c.pos] <= '9' {
+			c.pos++
+		}
+		return string(c.src[start:c.pos])
+	}
+
+	whole := digits()
+	var frac string
+	hasPoint := c.at(0) == '.'
+	if hasPoint {
+		c.pos++
+		frac = digits()
+	}
+	if whole == "" && frac == "" {
+		return c.errorf("invalid number")
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	c.out.WriteString(sign + whole)
+	if frac != "" {
+		c.out.WriteString("." + frac)
+	}
+	if c.at(0) == 'e' || c.at(0) == 'E' {
+		c.out.WriteRune(c.src[c.pos])
+		c.pos++
+		if c.at(0) == '+' || c.at(0) == '-' {
+			c.out.WriteRune(c.src[c.pos])
+			c.pos++
+		}
+		c.out.WriteString(digits())
+	}
+	return nil
+}
+
+// word reads an unquoted word: an object key, which is written quoted,
+// or one of the literals true, false and null
+func (c *json5Converter) word() error {
+	start := c.pos
+	for c.pos < len(c.src) {
+		r := c.src[c.pos]
+		if r != '$' && r != '_' && r != '\u200C' && r != '\u200D' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) {
+			break
+		}
+		c.pos++
+	}
+	word := string(c.src[start:c.pos])
+
+	if c.next() == ':' {
+		c.out.WriteString(quoteString(word))
+		return nil
+	}
+	switch word {
+	case "true", "false", "null":
+		c.out.WriteString(word)
+		return nil
+	case "Infinity", "NaN":
+		c.pos = start
+		return c.errorf("%s can't be represented in JSON", word)
+	default:
+		c.pos = start
+		return c.errorf("unexpected %s", word)
+	}
+}
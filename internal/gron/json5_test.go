@@ -0,0 +1,92 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSON5ToJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"// settings\n{\"a\": 1, /* b */ \"c\": [1, 2,],}", " \n{\"a\": 1,   \"c\": [1, 2]}"},
+		{"{a: 1, $b_2: 'x'}", `{"a": 1, "$b_2": "x"}`},
+		{`['it\'s', "say \"hi\"", '"']`, `["it's", "say \"hi\"", "\""]`},
+		{`[0x1F, -0Xff, +1, .5, 5., -.5e-3, 1E+2]`, `[31, -255, 1, 0.5, 5, -0.5e-3, 1E+2]`},
+		{`[0x10000000000000000]`, `[18446744073709551616]`},
+		{"['a\\\nb', '\\x41\\u00e9\\uD83D\\uDE00', '\\0', '\\q']", `["ab", "Aé😀", "\u0000", "q"]`},
+		{"{\u00a0a:\ttrue,\r\nb: false, c: null}", "{ \"a\":\ttrue,\r\n\"b\": false, \"c\": null}"},
+		{"{\"/*\": '//'}", `{"/*": "//"}`},
+	}
+
+	for _, c := range cases {
+		have, err := json5ToJSON([]byte(c.in))
+		if err != nil {
+			t.Fatalf("failed to convert %q: %s", c.in, err)
+		}
+		if string(have) != c.want {
+			t.Errorf("want %q for %q; have %q", c.want, c.in, have)
+		}
+	}
+}
+
+func TestJSON5ToJSONErrors(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{`{a: Infinity}`, "line 1, column 5: Infinity can't be represented in JSON"},
+		{`[-Infinity]`, "line 1, column 3: Infinity can't be represented in JSON"},
+		{"{\n  a: NaN}", "line 2, column 6: NaN can't be represented in JSON"},
+		{`{a: undefined}`, "unexpected undefined"},
+		{`{a: 'x}`, "string is not closed"},
+		{"{a: 'x\n'}", "string is not closed before the end of the line"},
+		{`{a: 1} /* x`, "comment is not closed"},
+		{`{a: 1 / 2}`, "unexpected /"},
+		{`['\01']`, "octal escapes are not allowed"},
+		{`['\xZZ']`, `invalid escape \xZZ`},
+		{`[-]`, "invalid number"},
+	}
+
+	for _, c := range cases {
+		_, err := json5ToJSON([]byte(c.in))
+		if err == nil {
+			t.Errorf("want error for %q; have none", c.in)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("want error containing %q for %q; have %q", c.want, c.in, err)
+		}
+	}
+}
+
+func TestGronJSON5(t *testing.T) {
+	in := `// tsconfig.json
+{
+  "compilerOptions": {
+    "target": "es2020", // the newest we support
+    "strict": true,
+  },
+  include: ['src/**/*',],
+}
+`
+	want := `json = {};
+json.compilerOptions = {};
+json.compilerOptions.target = "es2020";
+json.compilerOptions.strict = true;
+json.include = [];
+json.include[0] = "src/**/*";
+`
+
+	out := &bytes.Buffer{}
+	code, err := Gron(strings.NewReader(in), out, StatementToString, InputJSON5, false, false, GronOptions{})
+	if code != exitOK || err != nil {
+		t.Fatalf("failed to gron JSON5: %d, %s", code, err)
+	}
+	if out.String() != want {
+		t.Errorf("want:\n%s\nhave:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,253 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// A ConflictPolicy decides which value is kept when two documents
+// have different values at the same path
+type ConflictPolicy int
+
+const (
+	// ConflictLastWins keeps the value from the later document
+	ConflictLastWins ConflictPolicy = iota
+
+	// ConflictFirstWins keeps the value from the earlier document
+	ConflictFirstWins
+
+	// ConflictError fails the merge if there are any conflicts
+	ConflictError
+)
+
+// ConflictPolicyFromString returns the ConflictPolicy for a name as
+// accepted on the command line
+func ConflictPolicyFromString(name string) (ConflictPolicy, error) {
+	switch strings.ToLower(name) {
+	case "", "last", "last-wins":
+		return ConflictLastWins, nil
+	case "first", "first-wins":
+		return ConflictFirstWins, nil
+	case "error":
+		return ConflictError, nil
+	default:
+		return ConflictLastWins, fmt.Errorf("unknown conflict policy `%s`", name)
+	}
+}
+
+// An ArrayStrategy decides how two arrays at the same path are merged
+type ArrayStrategy int
+
+const (
+	// ArrayMergeByIndex merges the elements at each index, the same
+	// way that ungron merges statements
+	ArrayMergeByIndex ArrayStrategy = iota
+
+	// ArrayReplace treats arrays like scalar values, so the conflict
+	// policy decides which of the two arrays is kept
+	ArrayReplace
+
+	// ArrayConcat appends the elements of the later array to the earlier one
+	ArrayConcat
+
+	// ArrayMergeByKey merges objects with the same value for the key
+	// field and appends all other elements
+	ArrayMergeByKey
+)
+
+// ArrayStrategyFromString returns the ArrayStrategy for a name as
+// accepted on the command line
+func ArrayStrategyFromString(name string) (ArrayStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", "index":
+		return ArrayMergeByIndex, nil
+	case "replace":
+		return ArrayReplace, nil
+	case "concat", "concatenate":
+		return ArrayConcat, nil
+	case "key":
+		return ArrayMergeByKey, nil
+	default:
+		return ArrayMergeByIndex, fmt.Errorf("unknown array strategy `%s`", name)
+	}
+}
+
+// MergeOptions controls how documents are merged
+type MergeOptions struct {
+	Conflicts ConflictPolicy
+	Arrays    ArrayStrategy
+
+	// ArrayKey is the field used to match objects with ArrayMergeByKey
+	ArrayKey string
+}
+
+// A Conflict is a path that has different values in two merged documents
+type Conflict struct {
+	Path  Statement
+	First interface{}
+	Last  interface{}
+}
+
+// String returns a description of the conflict; e.g. json.a: 1 and 2
+func (c Conflict) String() string {
+	return fmt.Sprintf(
+		"%s: %s and %s",
+		c.Path,
+		valueTokenFromInterface(c.First).Text,
+		valueTokenFromInterface(c.Last).Text,
+	)
+}
+
+// merger holds the state for merging documents
+type merger struct {
+	opts      MergeOptions
+	conflicts []Conflict
+}
+
+// MergeValues merges b into a according to the options and returns
+// the result along with every path where the two had conflicting
+// values. Neither a nor b is modified. With ConflictError an error
+// is returned if there are any conflicts
+func MergeValues(a, b interface{}, opts MergeOptions) (interface{}, []Conflict, error) {
+	if opts.Arrays == ArrayMergeByKey && opts.ArrayKey == "" {
+		return nil, nil, errors.New("merging arrays by key needs a key field")
+	}
+
+	m := &merger{opts: opts}
+	out := m.merge(Statement{{"json", TypBare}}, deepCopy(a), b)
+
+	if opts.Conflicts == ConflictError && len(m.conflicts) > 0 {
+		return nil, m.conflicts, fmt.Errorf("%d conflicting values", len(m.conflicts))
+	}
+	return out, m.conflicts, nil
+}
+
+// merge merges b into a, where a is a value that can be modified
+func (m *merger) merge(path Statement, a, b interface{}) interface{} {
+	if isObject(a) && isObject(b) {
+		out := append(json.OrderedObject{}, objectMembers(a)...)
+		for _, bm := range objectMembers(b) {
+			av, exists := objectGet(out, bm.Key)
+			if !exists {
+				out = append(out, json.Member{Key: bm.Key, Value: deepCopy(bm.Value)})
+				continue
+			}
+			out = objectSet(out, bm.Key, m.merge(path.withKey(bm.Key), av, bm.Value)).(json.OrderedObject)
+		}
+		return out
+	}
+
+	aArr, aIsArr := a.([]interface{})
+	bArr, bIsArr := b.([]interface{})
+	if aIsArr && bIsArr {
+		switch m.opts.Arrays {
+		case ArrayConcat:
+			return append(aArr, deepCopy(bArr).([]interface{})...)
+
+		case ArrayMergeByKey:
+			return m.mergeByKey(path, aArr, bArr)
+
+		case ArrayMergeByIndex:
+			for i, bv := range bArr {
+				if i < len(aArr) {
+					aArr[i] = m.merge(path.withNumericKey(i), aArr[i], bv)
+				} else {
+					aArr = append(aArr, deepCopy(bv))
+				}
+			}
+			return aArr
+		}
+	}
+
+	return m.resolve(path, a, b)
+}
+
+// mergeByKey merges the objects in b into the objects in a that have
+// the same value for the key field, appending everything else
+func (m *merger) mergeByKey(path Statement, a, b []interface{}) []interface{} {
+	for _, bv := range b {
+		bKey, hasKey := objectGet(bv, m.opts.ArrayKey)
+
+		matched := false
+		for i, av := range a {
+			aKey, ok := objectGet(av, m.opts.ArrayKey)
+			if hasKey && ok && valuesEqual(aKey, bKey) {
+				a[i] = m.merge(path.withNumericKey(i), av, bv)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			a = append(a, deepCopy(bv))
+		}
+	}
+	return a
+}
+
+// resolve decides between two values that can't be merged,
+// recording a conflict if they're different
+func (m *merger) resolve(path Statement, a, b interface{}) interface{} {
+	if valuesEqual(a, b) {
+		return a
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{Path: path, First: a, Last: b})
+	if m.opts.Conflicts == ConflictFirstWins {
+		return a
+	}
+	return deepCopy(b)
+}
+
+// Merge reads several documents and merges them in order, writing the
+// result as JSON or YAML. formats gives the format of each input.
+// Conflicts are written to the report writer if it isn't nil
+func Merge(
+	rs []io.Reader,
+	formats []InputFormat,
+	w io.Writer,
+	report io.Writer,
+	opts MergeOptions,
+	colorize bool,
+	outYaml bool,
+) (int, error) {
+	var merged interface{}
+	for i, r := range rs {
+		var doc interface{}
+		err := MakeDecoder(r, formats[i], false).Decode(&doc)
+		if err != nil {
+			return exitReadInput, fmt.Errorf("failed to decode input %d: %s", i+1, err)
+		}
+
+		if i == 0 {
+			merged = doc
+			continue
+		}
+
+		var conflicts []Conflict
+		merged, conflicts, err = MergeValues(merged, doc, opts)
+		if report != nil {
+			for _, c := range conflicts {
+				fmt.Fprintf(report, "conflict merging input %d at %s\n", i+1, c)
+			}
+		}
+		if err != nil {
+			return exitConflict, errors.Wrapf(err, "failed to merge input %d", i+1)
+		}
+	}
+
+	var err error
+	if outYaml {
+		err = encodeYAML(w, merged)
+	} else {
+		err = writeJSON(w, merged, colorize, UngronOptions{})
+	}
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write merged document")
+	}
+	return exitOK, nil
+}
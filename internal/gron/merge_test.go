@@ -0,0 +1,130 @@
+package gron
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+func TestMergeValues(t *testing.T) {
+	cases := []struct {
+		a         string
+		b         string
+		opts      MergeOptions
+		want      string
+		conflicts []string
+	}{
+		{`{"a":1,"b":{"c":2}}`, `{"b":{"d":3},"e":4}`, MergeOptions{}, `{"a":1,"b":{"c":2,"d":3},"e":4}`, nil},
+		{`{"a":1}`, `{"a":2}`, MergeOptions{}, `{"a":2}`, []string{"json.a: 1 and 2"}},
+		{`{"a":1}`, `{"a":2}`, MergeOptions{Conflicts: ConflictFirstWins}, `{"a":1}`, []string{"json.a: 1 and 2"}},
+		{`{"a":1.0}`, `{"a":1}`, MergeOptions{}, `{"a":1.0}`, nil},
+		{`{"a":{"b":1}}`, `{"a":"x"}`, MergeOptions{}, `{"a":"x"}`, []string{`json.a: {} and "x"`}},
+		{`{"a b":[1,2,3]}`, `{"a b":[4]}`, MergeOptions{}, `{"a b":[4,2,3]}`, []string{`json["a b"][0]: 1 and 4`}},
+		{`{"a":[1,2,3]}`, `{"a":[4]}`, MergeOptions{Arrays: ArrayReplace}, `{"a":[4]}`, []string{"json.a: [] and []"}},
+		{`{"a":[1,2]}`, `{"a":[2,3]}`, MergeOptions{Arrays: ArrayConcat}, `{"a":[1,2,2,3]}`, nil},
+		{
+			`{"a":[{"id":1,"x":1},{"id":2,"x":2}]}`,
+			`{"a":[{"id":2,"y":3},{"id":3},{"x":4}]}`,
+			MergeOptions{Arrays: ArrayMergeByKey, ArrayKey: "id"},
+			`{"a":[{"id":1,"x":1},{"id":2,"x":2,"y":3},{"id":3},{"x":4}]}`,
+			nil,
+		},
+		{
+			`{"a":[{"id":1,"x":1}]}`,
+			`{"a":[{"id":1,"x":5}]}`,
+			MergeOptions{Arrays: ArrayMergeByKey, ArrayKey: "id"},
+			`{"a":[{"id":1,"x":5}]}`,
+			[]string{"json.a[0].x: 1 and 5"},
+		},
+	}
+
+	for _, c := range cases {
+		a := decodeOrdered(t, c.a)
+		have, conflicts, err := MergeValues(a, decodeOrdered(t, c.b), c.opts)
+		if err != nil {
+			t.Fatalf("want nil error merging %s and %s; have %s", c.a, c.b, err)
+		}
+
+		j, err := json.Marshal(have)
+		if err != nil {
+			t.Fatalf("failed to marshal merged value: %s", err)
+		}
+		if string(j) != c.want {
+			t.Errorf("want %s merging %s and %s; have %s", c.want, c.a, c.b, j)
+		}
+
+		if len(conflicts) != len(c.conflicts) {
+			t.Fatalf("want %d conflicts merging %s and %s; have %v", len(c.conflicts), c.a, c.b, conflicts)
+		}
+		for i, conflict := range conflicts {
+			if conflict.String() != c.conflicts[i] {
+				t.Errorf("want conflict `%s`; have `%s`", c.conflicts[i], conflict)
+			}
+		}
+
+		if !valuesEqual(a, decodeOrdered(t, c.a)) {
+			t.Errorf("merging modified the first document %s", c.a)
+		}
+	}
+}
+
+func TestMergeValuesError(t *testing.T) {
+	a := decodeOrdered(t, `{"a":1,"b":[1],"c":{"d":true}}`)
+	b := decodeOrdered(t, `{"a":1,"b":[2],"c":{"d":false}}`)
+
+	_, conflicts, err := MergeValues(a, b, MergeOptions{Conflicts: ConflictError})
+	if err == nil {
+		t.Fatalf("want non-nil error for conflicting values")
+	}
+
+	want := []string{"json.b[0]: 1 and 2", "json.c.d: true and false"}
+	if len(conflicts) != len(want) {
+		t.Fatalf("want conflicts %v; have %v", want, conflicts)
+	}
+	for i, conflict := range conflicts {
+		if conflict.String() != want[i] {
+			t.Errorf("want conflict `%s`; have `%s`", want[i], conflict)
+		}
+	}
+
+	_, _, err = MergeValues(a, b, MergeOptions{Arrays: ArrayMergeByKey})
+	if err == nil {
+		t.Errorf("want non-nil error merging by key without a key field")
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	inputs := []string{
+		"{name: 'app', ports: [80,], // defaults\n}",
+		"name: other\nports: [443]\ndebug: true\n",
+	}
+	out := &bytes.Buffer{}
+	report := &bytes.Buffer{}
+
+	code, err := Merge(
+		[]io.Reader{strings.NewReader(inputs[0]), strings.NewReader(inputs[1])},
+		[]InputFormat{InputJSON5, InputYAML},
+		out,
+		report,
+		MergeOptions{Arrays: ArrayConcat},
+		false,
+		false,
+	)
+	if code != exitOK {
+		t.Errorf("want exitOK; have %d", code)
+	}
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	want := "{\n  \"name\": \"other\",\n  \"ports\": [\n    80,\n    443\n  ],\n  \"debug\": true\n}\n"
+	if out.String() != want {
+		t.Errorf("want merged output %q; have %q", want, out.String())
+	}
+	if report.String() != "conflict merging input 2 at json.name: \"app\" and \"other\"\n" {
+		t.Errorf("unexpected conflict report %q", report.String())
+	}
+}
@@ -0,0 +1,607 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// A PatchOp is a single RFC 6902 JSON Patch operation
+type PatchOp struct {
+	Op    string      // add, remove, replace, move, copy or test
+	Path  string      // JSON Pointer to the target location
+	From  string      // JSON Pointer to the source location for move and copy
+	Value interface{} // The value for add, replace and test
+}
+
+// MarshalJSON encodes the operation with only the members that
+// apply to it, so that a null value is kept for add and replace
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	o := json.OrderedObject{
+		{Key: "op", Value: op.Op},
+		{Key: "path", Value: op.Path},
+	}
+	switch op.Op {
+	case "move", "copy":
+		o = append(o, json.Member{Key: "from", Value: op.From})
+	case "add", "replace", "test":
+		o = append(o, json.Member{Key: "value", Value: op.Value})
+	}
+	return json.Marshal(o)
+}
+
+// A JSONPatch is a list of operations that are applied in order
+type JSONPatch []PatchOp
+
+// DiffPatch returns a JSON Patch that turns a into b. It is built
+// from the statement differences between the two values, replacing
+// whole subtrees where the type of a value has changed
+func DiffPatch(a, b interface{}) (JSONPatch, error) {
+	prefix := Statement{{"json", TypBare}}
+	aSS := make(Statements, 0, 32)
+	aSS.fill(prefix, a)
+	bSS := make(Statements, 0, 32)
+	bSS.fill(prefix, b)
+
+	var removes, replaces, adds JSONPatch
+	covered := make(map[string]bool)
+
+	for _, d := range DiffStatements(aSS, bSS) {
+		keys, err := d.statement().Path()
+		if err != nil {
+			return nil, err
+		}
+
+		// Anything beneath a path that has already been
+		// added, removed or replaced is taken care of
+		isCovered := false
+		for i := 0; i <= len(keys); i++ {
+			if covered[jsonPointer(keys[:i])] {
+				isCovered = true
+				break
+			}
+		}
+		if isCovered {
+			continue
+		}
+		covered[jsonPointer(keys)] = true
+
+		switch d.Op {
+		case DiffRemoved:
+			removes = append(removes, PatchOp{Op: "remove", Path: jsonPointer(keys)})
+		case DiffAdded:
+			v, _ := valueAt(b, keys)
+			adds = append(adds, PatchOp{Op: "add", Path: jsonPointer(keys), Value: v})
+		case DiffChanged:
+			v, _ := valueAt(b, keys)
+			replaces = append(replaces, PatchOp{Op: "replace", Path: jsonPointer(keys), Value: v})
+		}
+	}
+
+	// Only trailing array elements can be removed, so they're removed
+	// from the end first so that the indexes of the others stay valid
+	for i, j := 0, len(removes)-1; i < j; i, j = i+1, j-1 {
+		removes[i], removes[j] = removes[j], removes[i]
+	}
+
+	patch := make(JSONPatch, 0, len(removes)+len(replaces)+len(adds))
+	patch = append(patch, removes...)
+	patch = append(patch, replaces...)
+	patch = append(patch, adds...)
+	return patch, nil
+}
+
+// hasKeyPrefix returns true if the path keys start with the prefix keys
+func hasKeyPrefix(keys, prefix []interface{}) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+	for i := range prefix {
+		if keys[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// valueAt returns the value at the given path keys
+func valueAt(v interface{}, keys []interface{}) (interface{}, bool) {
+	for _, k := range keys {
+		var ok bool
+		switch kk := k.(type) {
+		case string:
+			v, ok = objectGet(v, kk)
+		case int:
+			arr, isArr := v.([]interface{})
+			ok = isArr && kk >= 0 && kk < len(arr)
+			if ok {
+				v = arr[kk]
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// objectGet returns the member of an object with the given key
+func objectGet(o interface{}, key string) (interface{}, bool) {
+	switch oo := o.(type) {
+	case json.OrderedObject:
+		for _, m := range oo {
+			if m.Key == key {
+				return m.Value, true
+			}
+		}
+	case map[string]interface{}:
+		v, ok := oo[key]
+		return v, ok
+	}
+	return nil, false
+}
+
+// objectSet sets the member of an object with the given key,
+// appending it to ordered objects if it doesn't exist yet
+func objectSet(o interface{}, key string, v interface{}) interface{} {
+	switch oo := o.(type) {
+	case json.OrderedObject:
+		for i, m := range oo {
+			if m.Key == key {
+				oo[i].Value = v
+				return oo
+			}
+		}
+		return append(oo, json.Member{Key: key, Value: v})
+	case map[string]interface{}:
+		oo[key] = v
+		return oo
+	}
+	return o
+}
+
+// objectDelete removes the member of an object with the given key
+func objectDelete(o interface{}, key string) interface{} {
+	switch oo := o.(type) {
+	case json.OrderedObject:
+		for i, m := range oo {
+			if m.Key == key {
+				return append(oo[:i:i], oo[i+1:]...)
+			}
+		}
+	case map[string]interface{}:
+		delete(oo, key)
+	}
+	return o
+}
+
+// isObject returns true for the types used to represent JSON objects
+func isObject(v interface{}) bool {
+	switch v.(type) {
+	case json.OrderedObject, map[string]interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into its unescaped
+// reference tokens. The empty pointer refers to the whole document
+func parseJSONPointer(p string) ([]string, error) {
+	if p == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer `%s`", p)
+	}
+	r := strings.NewReplacer("~1", "/", "~0", "~")
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = r.Replace(t)
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses a JSON Pointer reference token as an index into
+// an array of length n. If allowEnd is set the index may be n or "-",
+// referring to the position after the last element
+func arrayIndex(token string, n int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index `%s`", token)
+	}
+	if i > n || (i == n && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// a containerFn performs an operation on the member of a
+// container value that a JSON Pointer refers to, returning
+// the updated container
+type containerFn func(container interface{}, token string) (interface{}, error)
+
+// applyAt walks down a document following the reference tokens and
+// calls fn with the container holding the last token. The containers
+// along the way are updated with the results
+func applyAt(doc interface{}, tokens []string, fn containerFn) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+
+	switch dd := doc.(type) {
+	case []interface{}:
+		i, err := arrayIndex(tokens[0], len(dd), false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := applyAt(dd[i], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		dd[i] = newChild
+		return dd, nil
+
+	default:
+		if !isObject(doc) {
+			return nil, fmt.Errorf("cannot find `%s` in a scalar value", tokens[0])
+		}
+		child, ok := objectGet(doc, tokens[0])
+		if !ok {
+			return nil, fmt.Errorf("no member named `%s`", tokens[0])
+		}
+		newChild, err := applyAt(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		return objectSet(doc, tokens[0], newChild), nil
+	}
+}
+
+// patchAdd adds a value at a JSON Pointer; inserting into arrays
+func patchAdd(doc interface{}, tokens []string, v interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	return applyAt(doc, tokens, func(c interface{}, token string) (interface{}, error) {
+		if arr, ok := c.([]interface{}); ok {
+			i, err := arrayIndex(token, len(arr), true)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, nil)
+			copy(arr[i+1:], arr[i:])
+			arr[i] = v
+			return arr, nil
+		}
+		if !isObject(c) {
+			return nil, fmt.Errorf("cannot add `%s` to a scalar value", token)
+		}
+		return objectSet(c, token, v), nil
+	})
+}
+
+// patchRemove removes the value at a JSON Pointer
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return applyAt(doc, tokens, func(c interface{}, token string) (interface{}, error) {
+		if arr, ok := c.([]interface{}); ok {
+			i, err := arrayIndex(token, len(arr), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(arr[:i:i], arr[i+1:]...), nil
+		}
+		if _, ok := objectGet(c, token); !ok {
+			return nil, fmt.Errorf("no member named `%s`", token)
+		}
+		return objectDelete(c, token), nil
+	})
+}
+
+// patchReplace replaces the existing value at a JSON Pointer
+func patchReplace(doc interface{}, tokens []string, v interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	return applyAt(doc, tokens, func(c interface{}, token string) (interface{}, error) {
+		if arr, ok := c.([]interface{}); ok {
+			i, err := arrayIndex(token, len(arr), false)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+			return arr, nil
+		}
+		if _, ok := objectGet(c, token); !ok {
+			return nil, fmt.Errorf("no member named `%s`", token)
+		}
+		return objectSet(c, token, v), nil
+	})
+}
+
+// patchGet returns the value at a JSON Pointer
+func patchGet(doc interface{}, tokens []string) (interface{}, error) {
+	var out interface{}
+	if len(tokens) == 0 {
+		return doc, nil
+	}
+	_, err := applyAt(doc, tokens, func(c interface{}, token string) (interface{}, error) {
+		if arr, ok := c.([]interface{}); ok {
+			i, err := arrayIndex(token, len(arr), false)
+			if err != nil {
+				return nil, err
+			}
+			out = arr[i]
+			return c, nil
+		}
+		v, ok := objectGet(c, token)
+		if !ok {
+			return nil, fmt.Errorf("no member named `%s`", token)
+		}
+		out = v
+		return c, nil
+	})
+	return out, err
+}
+
+// ApplyPatch applies the operations of a JSON Patch to a document in
+// order, returning the patched document. The document may be modified
+func ApplyPatch(doc interface{}, patch JSONPatch) (interface{}, error) {
+	for n, op := range patch {
+		tokens, err := parseJSONPointer(op.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "operation %d", n)
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = patchAdd(doc, tokens, op.Value)
+
+		case "remove":
+			doc, err = patchRemove(doc, tokens)
+
+		case "replace":
+			doc, err = patchReplace(doc, tokens, op.Value)
+
+		case "move", "copy":
+			var from []string
+			var v interface{}
+			from, err = parseJSONPointer(op.From)
+			if err == nil {
+				v, err = patchGet(doc, from)
+			}
+			if err == nil && op.Op == "move" {
+				if hasTokenPrefix(tokens, from) && len(tokens) > len(from) {
+					err = errors.New("cannot move a value into itself")
+				} else {
+					doc, err = patchRemove(doc, from)
+				}
+			}
+			if err == nil {
+				doc, err = patchAdd(doc, tokens, deepCopy(v))
+			}
+
+		case "test":
+			var v interface{}
+			v, err = patchGet(doc, tokens)
+			if err == nil && !valuesEqual(v, op.Value) {
+				err = fmt.Errorf("test failed for `%s`", op.Path)
+			}
+
+		default:
+			err = fmt.Errorf("unknown operation `%s`", op.Op)
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "operation %d (%s %s)", n, op.Op, op.Path)
+		}
+	}
+	return doc, nil
+}
+
+// hasTokenPrefix returns true if the reference tokens start with the prefix tokens
+func hasTokenPrefix(tokens, prefix []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if tokens[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// deepCopy returns a copy of a value that shares no containers with it
+func deepCopy(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		out := make(json.OrderedObject, len(vv))
+		for i, m := range vv {
+			out[i] = json.Member{Key: m.Key, Value: deepCopy(m.Value)}
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(vv))
+		for k, sub := range vv {
+			out[k] = deepCopy(sub)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(vv))
+		for i, sub := range vv {
+			out[i] = deepCopy(sub)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// valuesEqual compares two values the way JSON does: object member
+// order doesn't matter and numbers are compared by value
+func valuesEqual(a, b interface{}) bool {
+	if isObject(a) && isObject(b) {
+		aMembers := objectMembers(a)
+		bMembers := objectMembers(b)
+		if len(aMembers) != len(bMembers) {
+			return false
+		}
+		for _, m := range aMembers {
+			bv, ok := objectGet(b, m.Key)
+			if !ok || !valuesEqual(m.Value, bv) {
+				return false
+			}
+		}
+		return true
+	}
+
+	aArr, aIsArr := a.([]interface{})
+	bArr, bIsArr := b.([]interface{})
+	if aIsArr || bIsArr {
+		if !aIsArr || !bIsArr || len(aArr) != len(bArr) {
+			return false
+		}
+		for i := range aArr {
+			if !valuesEqual(aArr[i], bArr[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	aToken := valueTokenFromInterface(a)
+	bToken := valueTokenFromInterface(b)
+	if aToken.Typ == TypNumber && bToken.Typ == TypNumber {
+		na, errA := json.Number(aToken.Text).Float64()
+		nb, errB := json.Number(bToken.Text).Float64()
+		return errA == nil && errB == nil && na == nb
+	}
+	return aToken.Typ != TypError && aToken == bToken
+}
+
+// objectMembers returns the members of an object as a list
+func objectMembers(o interface{}) json.OrderedObject {
+	switch oo := o.(type) {
+	case json.OrderedObject:
+		return oo
+	case map[string]interface{}:
+		out := make(json.OrderedObject, 0, len(oo))
+		for k, v := range oo {
+			out = append(out, json.Member{Key: k, Value: v})
+		}
+		return out
+	}
+	return nil
+}
+
+// parsePatch converts a decoded JSON Patch document into a JSONPatch
+func parsePatch(v interface{}) (JSONPatch, error) {
+	ops, ok := v.([]interface{})
+	if !ok {
+		return nil, errors.New("a JSON Patch must be an array of operations")
+	}
+
+	patch := make(JSONPatch, 0, len(ops))
+	for n, o := range ops {
+		if !isObject(o) {
+			return nil, fmt.Errorf("operation %d is not an object", n)
+		}
+
+		var op PatchOp
+		name, _ := objectGet(o, "op")
+		path, hasPath := objectGet(o, "path")
+		op.Op, _ = name.(string)
+		op.Path, ok = path.(string)
+		if !hasPath || !ok {
+			return nil, fmt.Errorf("operation %d has no path", n)
+		}
+
+		switch op.Op {
+		case "move", "copy":
+			from, _ := objectGet(o, "from")
+			op.From, ok = from.(string)
+			if !ok {
+				return nil, fmt.Errorf("operation %d has no from", n)
+			}
+		case "add", "replace", "test":
+			op.Value, ok = objectGet(o, "value")
+			if !ok {
+				return nil, fmt.Errorf("operation %d has no value", n)
+			}
+		}
+		patch = append(patch, op)
+	}
+	return patch, nil
+}
+
+// DiffToPatch reads two documents, in the formats given for each, and
+// writes the JSON Patch that turns the first into the second. Like Diff
+// it returns exitDiffer if the documents are different
+func DiffToPatch(a io.Reader, b io.Reader, w io.Writer, aFormat InputFormat, bFormat InputFormat, colorize bool) (int, error) {
+	var aDoc, bDoc interface{}
+	err := MakeDecoder(a, aFormat, false).Decode(&aDoc)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to decode first input: %s", err)
+	}
+	err = MakeDecoder(b, bFormat, false).Decode(&bDoc)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to decode second input: %s", err)
+	}
+
+	patch, err := DiffPatch(aDoc, bDoc)
+	if err != nil {
+		return exitFormStatements, errors.Wrap(err, "failed to create patch")
+	}
+
+	err = writeJSON(w, patch, colorize, UngronOptions{})
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to convert patch to JSON")
+	}
+
+	if len(patch) > 0 {
+		return exitDiffer, nil
+	}
+	return exitOK, nil
+}
+
+// Patch reads a document in a format and a JSON Patch, and writes the
+// patched document as JSON
+func Patch(doc io.Reader, patch io.Reader, w io.Writer, format InputFormat, colorize bool) (int, error) {
+	var d, p interface{}
+	err := MakeDecoder(doc, format, false).Decode(&d)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to decode document: %s", err)
+	}
+	err = MakeDecoder(patch, InputJSON, false).Decode(&p)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to decode patch: %s", err)
+	}
+
+	ops, err := parsePatch(p)
+	if err != nil {
+		return exitParseStatements, err
+	}
+
+	patched, err := ApplyPatch(d, ops)
+	if err != nil {
+		return exitParseStatements, errors.Wrap(err, "failed to apply patch")
+	}
+
+	err = writeJSON(w, patched, colorize, UngronOptions{})
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to convert patched document to JSON")
+	}
+	return exitOK, nil
+}
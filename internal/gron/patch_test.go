@@ -0,0 +1,124 @@
+package gron
+
+import (
+	"strings"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+func decodeOrdered(t *testing.T, s string) interface{} {
+	var v interface{}
+	err := MakeDecoder(strings.NewReader(s), InputJSON, false).Decode(&v)
+	if err != nil {
+		t.Fatalf("failed to decode `%s`: %s", s, err)
+	}
+	return v
+}
+
+func TestDiffPatch(t *testing.T) {
+	cases := []struct {
+		a    string
+		b    string
+		want string
+	}{
+		{`{"a":1}`, `{"a":1}`, `[]`},
+		{`{"a":1}`, `{"a":2}`, `[{"op":"replace","path":"/a","value":2}]`},
+		{`{"a":1,"b":{"c":[1,2]}}`, `{"a":1}`, `[{"op":"remove","path":"/b"}]`},
+		{`{"a":1}`, `{"a":1,"b":{"c":[1,null]}}`, `[{"op":"add","path":"/b","value":{"c":[1,null]}}]`},
+		{`{"a":[1,2,3,4]}`, `{"a":[1,5]}`, `[{"op":"remove","path":"/a/3"},{"op":"remove","path":"/a/2"},{"op":"replace","path":"/a/1","value":5}]`},
+		{`{"a":[1]}`, `{"a":[1,2,3]}`, `[{"op":"add","path":"/a/1","value":2},{"op":"add","path":"/a/2","value":3}]`},
+		{`{"a":{"x":1}}`, `{"a":["x"]}`, `[{"op":"replace","path":"/a","value":["x"]}]`},
+		{`{"a/b":{"~":1}}`, `{"a/b":{"~":null}}`, `[{"op":"replace","path":"/a~1b/~0","value":null}]`},
+		{`[1]`, `{"a":1}`, `[{"op":"replace","path":"","value":{"a":1}}]`},
+	}
+
+	for _, c := range cases {
+		a := decodeOrdered(t, c.a)
+		b := decodeOrdered(t, c.b)
+
+		patch, err := DiffPatch(a, b)
+		if err != nil {
+			t.Fatalf("want nil error for %s -> %s; have %s", c.a, c.b, err)
+		}
+		have, err := json.Marshal(patch)
+		if err != nil {
+			t.Fatalf("failed to marshal patch: %s", err)
+		}
+		if string(have) != c.want {
+			t.Errorf("want patch %s for %s -> %s; have %s", c.want, c.a, c.b, have)
+		}
+
+		patched, err := ApplyPatch(a, patch)
+		if err != nil {
+			t.Fatalf("want nil error applying %s; have %s", have, err)
+		}
+		if !valuesEqual(patched, decodeOrdered(t, c.b)) {
+			t.Errorf("applying %s to %s does not give %s", have, c.a, c.b)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	cases := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`, `{"foo":["bar",["abc"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":{"bar":1},"baz":{"bar":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+	}
+
+	for _, c := range cases {
+		patch, err := parsePatch(decodeOrdered(t, c.patch))
+		if err != nil {
+			t.Fatalf("want nil error parsing %s; have %s", c.patch, err)
+		}
+
+		have, err := ApplyPatch(decodeOrdered(t, c.doc), patch)
+		if err != nil {
+			t.Fatalf("want nil error applying %s; have %s", c.patch, err)
+		}
+		if !valuesEqual(have, decodeOrdered(t, c.want)) {
+			t.Errorf("applying %s to %s; want %s have %#v", c.patch, c.doc, c.want, have)
+		}
+	}
+}
+
+func TestApplyPatchInvalid(t *testing.T) {
+	cases := []struct {
+		doc   string
+		patch string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/5","value":2}]`},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`},
+		{`{"foo":"bar"}`, `[{"op":"frobnicate","path":"/foo"}]`},
+	}
+
+	for _, c := range cases {
+		patch, err := parsePatch(decodeOrdered(t, c.patch))
+		if err != nil {
+			t.Fatalf("want nil error parsing %s; have %s", c.patch, err)
+		}
+
+		_, err = ApplyPatch(decodeOrdered(t, c.doc), patch)
+		if err == nil {
+			t.Errorf("want non-nil error applying %s to %s; have nil", c.patch, c.doc)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// A PathFormat is a syntax that the path of a statement can be written in
+type PathFormat int
+
+const (
+	// PathGron is gron's own syntax; e.g. json.foo[0]
+	PathGron PathFormat = iota
+
+	// PathJSONPath is a JSONPath expression; e.g. $.foo[0]
+	PathJSONPath
+
+	// PathJSONPointer is an RFC 6901 JSON Pointer; e.g. /foo/0
+	PathJSONPointer
+
+	// PathJQ is a jq filter; e.g. .foo[0]
+	PathJQ
+)
+
+// PathFormatFromString returns the PathFormat for a name as
+// accepted on the command line
+func PathFormatFromString(name string) (PathFormat, error) {
+	switch strings.ToLower(name) {
+	case "", "gron":
+		return PathGron, nil
+	case "jsonpath":
+		return PathJSONPath, nil
+	case "pointer", "jsonpointer", "json-pointer":
+		return PathJSONPointer, nil
+	case "jq":
+		return PathJQ, nil
+	default:
+		return PathGron, fmt.Errorf("unknown path format `%s`", name)
+	}
+}
+
+// simpleKey matches keys that can be written without quoting
+// in both JSONPath and jq dot notation
+var simpleKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Path returns the keys making up the path of a statement, not
+// including the leading bare word. Object keys are returned as
+// strings and array indexes as ints
+// E.g:
+//
+//	json.foo["bar baz"][2] = 1; -> ["foo", "bar baz", 2]
+func (s Statement) Path() ([]interface{}, error) {
+	if len(s) == 0 || s[0].Typ != TypBare {
+		return nil, errors.New("statement does not start with a bare word")
+	}
+
+	keys := make([]interface{}, 0, len(s)/3)
+	for _, t := range s[1:] {
+		switch t.Typ {
+		case TypBare:
+			keys = append(keys, t.Text)
+		case TypQuotedKey:
+			var k string
+			err := json.Unmarshal([]byte(t.Text), &k)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted key `%s`", t.Text)
+			}
+			keys = append(keys, k)
+		case TypNumericKey:
+			k, err := strconv.Atoi(t.Text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer key `%s`", t.Text)
+			}
+			keys = append(keys, k)
+		case TypDot, TypLBrace, TypRBrace:
+			// Punctuation within the path
+		case TypEquals:
+			return keys, nil
+		default:
+			return nil, fmt.Errorf("unexpected token `%s` in path", t.Text)
+		}
+	}
+	return keys, nil
+}
+
+// JSONPath returns the path of a statement as a JSONPath expression.
+// Keys that are not simple identifiers use the bracket notation, which
+// is also understood by kubectl's -o jsonpath
+func (s Statement) JSONPath() (string, error) {
+	keys, err := s.Path()
+	if err != nil {
+		return "", err
+	}
+
+	out := &strings.Builder{}
+	out.WriteString("$")
+	for _, k := range keys {
+		switch kk := k.(type) {
+		case int:
+			fmt.Fprintf(out, "[%d]", kk)
+		case string:
+			if simpleKey.MatchString(kk) {
+				out.WriteString("." + kk)
+				continue
+			}
+			r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+			out.WriteString("['" + r.Replace(kk) + "']")
+		}
+	}
+	return out.String(), nil
+}
+
+// JSONPointer returns the path of a statement as an RFC 6901 JSON Pointer
+func (s Statement) JSONPointer() (string, error) {
+	keys, err := s.Path()
+	if err != nil {
+		return "", err
+	}
+	return jsonPointer(keys), nil
+}
+
+// jsonPointer builds a JSON Pointer from a list of keys
+func jsonPointer(keys []interface{}) string {
+	out := &strings.Builder{}
+	r := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, k := range keys {
+		out.WriteString("/")
+		switch kk := k.(type) {
+		case int:
+			out.WriteString(strconv.Itoa(kk))
+		case string:
+			out.WriteString(r.Replace(kk))
+		}
+	}
+	return out.String()
+}
+
+// JQ returns the path of a statement as a jq filter
+func (s Statement) JQ() (string, error) {
+	keys, err := s.Path()
+	if err != nil {
+		return "", err
+	}
+
+	out := &strings.Builder{}
+	for _, k := range keys {
+		switch kk := k.(type) {
+		case int:
+			fmt.Fprintf(out, "[%d]", kk)
+		case string:
+			if simpleKey.MatchString(kk) {
+				out.WriteString("." + kk)
+				continue
+			}
+			out.WriteString("." + quoteString(kk))
+		}
+	}
+
+	p := out.String()
+	if !strings.HasPrefix(p, ".") {
+		p = "." + p
+	}
+	return p, nil
+}
+
+// PathString returns the path of a statement in the given format
+func (s Statement) PathString(f PathFormat) (string, error) {
+	switch f {
+	case PathJSONPath:
+		return s.JSONPath()
+	case PathJSONPointer:
+		return s.JSONPointer()
+	case PathJQ:
+		return s.JQ()
+	default:
+		for i, t := range s {
+			if t.Typ == TypEquals {
+				return Statement(s[:i]).String(), nil
+			}
+		}
+		return s.String(), nil
+	}
+}
+
+// StatementToPathConv returns a StatementConv that writes statements
+// with their path in the given format followed by their value;
+// e.g. $.foo[0] = "bar";
+// Statements without a path are written unchanged
+func StatementToPathConv(f PathFormat, colorize bool) StatementConv {
+	return func(s Statement) string {
+		p, err := s.PathString(f)
+		body := s.withoutComment()
+		if err != nil || len(body) < 3 || body[len(body)-3].Typ != TypEquals {
+			if colorize {
+				return s.colorString()
+			}
+			return s.String()
+		}
+
+		// The root JSON Pointer is the empty string
+		if p == "" {
+			p = `""`
+		}
+
+		// The tail is the assignment and any comment
+		tail := s[len(body)-3:]
+		if colorize {
+			return bareColor.Sprint(p) + tail.colorString()
+		}
+		return p + tail.String()
+	}
+}
@@ -0,0 +1,75 @@
+package gron
+
+import (
+	"testing"
+)
+
+func TestStatementPaths(t *testing.T) {
+	cases := []struct {
+		in       string
+		jsonPath string
+		pointer  string
+		jq       string
+	}{
+		{`json = {};`, `$`, ``, `.`},
+		{`json.foo = 1;`, `$.foo`, `/foo`, `.foo`},
+		{`json[0].foo = 1;`, `$[0].foo`, `/0/foo`, `.[0].foo`},
+		{`json.foo[2][10] = 1;`, `$.foo[2][10]`, `/foo/2/10`, `.foo[2][10]`},
+		{`json["a b"].c = 1;`, `$['a b'].c`, `/a b/c`, `."a b".c`},
+		{`json["it's"] = 1;`, `$['it\'s']`, `/it's`, `."it's"`},
+		{`json["a/b~c"] = 1;`, `$['a/b~c']`, `/a~1b~0c`, `."a/b~c"`},
+		{`json["quo\"te"] = 1;`, `$['quo"te']`, `/quo"te`, `."quo\"te"`},
+		{`json["back\\slash"] = 1;`, `$['back\\slash']`, `/back\slash`, `."back\\slash"`},
+		{`json.café = 1;`, `$['café']`, `/café`, `."café"`},
+		{`json[""] = 1;`, `$['']`, `/`, `.""`},
+	}
+
+	for _, c := range cases {
+		s := StatementFromString(c.in)
+
+		have, err := s.JSONPath()
+		if err != nil {
+			t.Errorf("want nil error for JSONPath of `%s`; have %s", c.in, err)
+		}
+		if have != c.jsonPath {
+			t.Errorf("want JSONPath `%s` for `%s`; have `%s`", c.jsonPath, c.in, have)
+		}
+
+		have, err = s.JSONPointer()
+		if err != nil {
+			t.Errorf("want nil error for JSONPointer of `%s`; have %s", c.in, err)
+		}
+		if have != c.pointer {
+			t.Errorf("want JSON Pointer `%s` for `%s`; have `%s`", c.pointer, c.in, have)
+		}
+
+		have, err = s.JQ()
+		if err != nil {
+			t.Errorf("want nil error for JQ of `%s`; have %s", c.in, err)
+		}
+		if have != c.jq {
+			t.Errorf("want jq `%s` for `%s`; have `%s`", c.jq, c.in, have)
+		}
+	}
+}
+
+func TestStatementToPathConv(t *testing.T) {
+	s := StatementFromString(`json.foo["bar baz"][0] = "x";`)
+
+	cases := []struct {
+		f    PathFormat
+		want string
+	}{
+		{PathGron, `json.foo["bar baz"][0] = "x";`},
+		{PathJSONPath, `$.foo['bar baz'][0] = "x";`},
+		{PathJSONPointer, `/foo/bar baz/0 = "x";`},
+		{PathJQ, `.foo."bar baz"[0] = "x";`},
+	}
+
+	for _, c := range cases {
+		have := StatementToPathConv(c.f, false)(s)
+		if have != c.want {
+			t.Errorf("want `%s`; have `%s`", c.want, have)
+		}
+	}
+}
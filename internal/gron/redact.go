@@ -0,0 +1,118 @@
+package gron
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// RedactedValue replaces the values of keys that are redacted
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys are the key patterns that are always redacted
+// when redaction is turned on. They match whole words of a key, and
+// the plural of those words
+var DefaultRedactKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"authorization",
+	"api_?key",
+}
+
+// A Redactor decides which values are replaced with RedactedValue:
+// those beneath a key matching one of its patterns, or beneath one of
+// its paths. The default patterns ignore case and match whole words of
+// a key, which are separated by punctuation or a change of case; e.g.
+// token matches access_token, accessToken and auth_tokens, but not
+// tokenizer. Other patterns are matched against the key as given
+type Redactor struct {
+	defaults []*regexp.Regexp
+	keys     []*regexp.Regexp
+	paths    [][]interface{}
+}
+
+// NewRedactor returns a Redactor for the default key patterns plus
+// the given key patterns and gron paths; e.g. json.users[0].email
+func NewRedactor(keys []string, paths []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, k := range DefaultRedactKeys {
+		r.defaults = append(r.defaults, regexp.MustCompile("(?:^|_)(?:"+k+")s?(?:_|$)"))
+	}
+	for _, k := range keys {
+		re, err := regexp.Compile(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact key pattern `%s`", k)
+		}
+		r.keys = append(r.keys, re)
+	}
+
+	for _, p := range paths {
+		keys, err := parsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, keys)
+	}
+	return r, nil
+}
+
+// redacts returns true if the value at a path should be redacted.
+// A nil Redactor redacts nothing
+func (r *Redactor) redacts(path Statement) bool {
+	if r == nil {
+		return false
+	}
+	keys, err := path.Path()
+	if err != nil {
+		return false
+	}
+
+	for _, p := range r.paths {
+		if hasKeyPrefix(keys, p) {
+			return true
+		}
+	}
+	for _, k := range keys {
+		ks, ok := k.(string)
+		if !ok {
+			continue
+		}
+		words := keyWords(ks)
+		for _, re := range r.defaults {
+			if re.MatchString(words) {
+				return true
+			}
+		}
+		for _, re := range r.keys {
+			if re.MatchString(ks) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyWords returns the words of a key in lower case, separated by
+// underscores; words are split by punctuation and wherever the case
+// changes. E.g. apiKey, APIKey and api-key all become api_key
+func keyWords(k string) string {
+	runes := []rune(k)
+	out := &strings.Builder{}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			out.WriteByte('_')
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				out.WriteByte('_')
+			}
+		}
+		out.WriteRune(unicode.ToLower(r))
+	}
+	return out.String()
+}
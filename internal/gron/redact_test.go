@@ -0,0 +1,95 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedactorRedacts(t *testing.T) {
+	r, err := NewRedactor([]string{"^e?mail$"}, []string{"json.users[0].name"})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"json.password", true},
+		{"json.db.Password", true},
+		{`json["api-key"]`, true},
+		{"json.apiKey", true},
+		{"json.headers.Authorization", true},
+		{"json.tokens[0].id", true},
+		{"json.passwords", true},
+		{"json.secrets", true},
+		{"json.auth_tokens", true},
+		{"json.apiKeys", true},
+		{"json.tokenizer", false},
+		{"json.accessToken", true},
+		{"json.APIKey", true},
+		{`json["X-Auth-Token"]`, true},
+		{"json.db_password_hash", true},
+		{"json.secretary", false},
+		{"json.apikeys", true},
+		{"json.Mail", false},
+		{"json.mail", true},
+		{"json.mailbox", false},
+		{"json.users[0].name", true},
+		{"json.users[0].name.first", true},
+		{"json.users[1].name", false},
+		{"json.user", false},
+	}
+
+	for _, c := range cases {
+		have := r.redacts(StatementFromString(c.path + " = null;"))
+		if have != c.want {
+			t.Errorf("want %t for %s; have %t", c.want, c.path, have)
+		}
+	}
+
+	var none *Redactor
+	if none.redacts(StatementFromString("json.password = null;")) {
+		t.Errorf("want a nil Redactor to redact nothing")
+	}
+}
+
+func TestNewRedactorInvalid(t *testing.T) {
+	_, err := NewRedactor([]string{"("}, nil)
+	if err == nil {
+		t.Errorf("want error for an invalid key pattern")
+	}
+
+	_, err = NewRedactor(nil, []string{"json.["})
+	if err == nil {
+		t.Errorf("want error for an invalid path")
+	}
+}
+
+func TestGronRedact(t *testing.T) {
+	in := `{"user":"sam","secret":{"key":"k","ids":[1,null]}}`
+	want := []string{
+		`json = {};`,
+		`json.user = "sam";`,
+		`json.secret = {};`,
+		`json.secret.key = "[REDACTED]";`,
+		`json.secret.ids = [];`,
+		`json.secret.ids[0] = "[REDACTED]";`,
+		`json.secret.ids[1] = "[REDACTED]";`,
+	}
+
+	r, err := NewRedactor(nil, nil)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	out := &bytes.Buffer{}
+	_, err = Gron(strings.NewReader(in), out, StatementToString, InputJSON, false, false, GronOptions{Redact: r})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	have := strings.TrimSpace(out.String())
+	if have != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\nhave:\n%s", strings.Join(want, "\n"), have)
+	}
+}
@@ -0,0 +1,305 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// A schemaNode summarises the values found at one path of some
+// documents, with the indexes of arrays collapsed into [*]
+type schemaNode struct {
+	path  Statement
+	key   string
+	types map[string]int
+
+	// integers counts the numbers found that have no fractional part
+	integers int
+
+	// objectKey is set when the path ends in an object key, so that
+	// the number of values that could have been found is the number
+	// of objects found at the parent
+	objectKey bool
+	parent    *schemaNode
+
+	members []*schemaNode
+	byKey   map[string]*schemaNode
+	elems   *schemaNode
+}
+
+// newSchemaNode returns an empty schemaNode for a path
+func newSchemaNode(path Statement, parent *schemaNode, objectKey bool) *schemaNode {
+	return &schemaNode{
+		path:      path,
+		types:     make(map[string]int),
+		objectKey: objectKey,
+		parent:    parent,
+		byKey:     make(map[string]*schemaNode),
+	}
+}
+
+// jsonType returns the name of the JSON type of a value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case json.OrderedObject, map[string]interface{}, map[interface{}]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// add records a value found at the node's path, along with
+// everything beneath it
+func (n *schemaNode) add(v interface{}) {
+	n.types[jsonType(v)]++
+	if jsonType(v) == "number" && !strings.ContainsAny(valueTokenFromInterface(v).Text, ".eE") {
+		n.integers++
+	}
+
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		for _, m := range vv {
+			n.member(m.Key).add(m.Value)
+		}
+	case map[string]interface{}:
+		for k, sub := range vv {
+			n.member(k).add(sub)
+		}
+	case map[interface{}]interface{}:
+		for k, sub := range vv {
+			n.member(fmt.Sprintf("%v", k)).add(sub)
+		}
+	case []interface{}:
+		for _, e := range vv {
+			n.elements().add(e)
+		}
+	}
+}
+
+// member returns the node for an object key beneath the node
+func (n *schemaNode) member(k string) *schemaNode {
+	if m, ok := n.byKey[k]; ok {
+		return m
+	}
+	m := newSchemaNode(n.path.withKey(k), n, true)
+	m.key = k
+	n.byKey[k] = m
+	n.members = append(n.members, m)
+	return m
+}
+
+// elements returns the node for the elements of arrays at the node
+func (n *schemaNode) elements() *schemaNode {
+	if n.elems == nil {
+		n.elems = newSchemaNode(n.path.withWildcardKey(), n, false)
+	}
+	return n.elems
+}
+
+// found returns the number of values found at the node
+func (n *schemaNode) found() int {
+	total := 0
+	for _, count := range n.types {
+		total += count
+	}
+	return total
+}
+
+// possible returns the number of values that could have been found at
+// the node: one for each object at the parent for object keys, or the
+// number found for array elements and the root
+func (n *schemaNode) possible() int {
+	if n.objectKey {
+		return n.parent.types["object"]
+	}
+	return n.found()
+}
+
+// typeNames returns the names of the types found at the node,
+// the most common first
+func (n *schemaNode) typeNames() []string {
+	names := make([]string, 0, len(n.types))
+	for name := range n.types {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if n.types[names[i]] != n.types[names[j]] {
+			return n.types[names[i]] > n.types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// summary returns the types found at the node with the number of
+// times each was found, the most common first.
+// E.g:
+//
+//	string (98/100, null 2/100)
+func (n *schemaNode) summary() string {
+	names := n.typeNames()
+	possible := n.possible()
+	out := fmt.Sprintf("%s (%d/%d", names[0], n.types[names[0]], possible)
+	for _, name := range names[1:] {
+		out += fmt.Sprintf(", %s %d/%d", name, n.types[name], possible)
+	}
+	return out + ")"
+}
+
+// statements returns a statement for the node and each node beneath it,
+// assigning the summary of the types found there
+func (n *schemaNode) statements() Statements {
+	ss := Statements{append(append(Statement{}, n.path...),
+		Token{"=", TypEquals},
+		Token{n.summary(), TypTypes},
+		Token{";", TypSemi},
+	)}
+	for _, m := range n.members {
+		ss = append(ss, m.statements()...)
+	}
+	if n.elems != nil {
+		ss = append(ss, n.elems.statements()...)
+	}
+	return ss
+}
+
+// jsonSchema returns a JSON Schema for the values found at the node.
+// Object keys found in at least the required fraction of the objects
+// at the node are required
+func (n *schemaNode) jsonSchema(required float64) json.OrderedObject {
+	var types []interface{}
+	for _, name := range n.typeNames() {
+		if name == "number" && n.integers == n.types[name] {
+			name = "integer"
+		}
+		types = append(types, name)
+	}
+
+	out := json.OrderedObject{}
+	switch len(types) {
+	case 0:
+	case 1:
+		out = append(out, json.Member{Key: "type", Value: types[0]})
+	default:
+		out = append(out, json.Member{Key: "type", Value: types})
+	}
+
+	if len(n.members) > 0 {
+		props := make(json.OrderedObject, 0, len(n.members))
+		var req []interface{}
+		for _, m := range n.members {
+			props = append(props, json.Member{Key: m.key, Value: m.jsonSchema(required)})
+			if float64(m.found())/float64(n.types["object"]) >= required {
+				req = append(req, m.key)
+			}
+		}
+		out = append(out, json.Member{Key: "properties", Value: props})
+		if len(req) > 0 {
+			out = append(out, json.Member{Key: "required", Value: req})
+		}
+	}
+
+	if n.elems != nil {
+		out = append(out, json.Member{Key: "items", Value: n.elems.jsonSchema(required)})
+	}
+	return out
+}
+
+// addDocuments adds every document read by a decoder to the node with
+// the options applied, returning how many were added
+func (n *schemaNode) addDocuments(d Decoder, opts GronOptions) (int, error) {
+	docs := 0
+	for {
+		var v interface{}
+		err := d.Decode(&v)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return docs, fmt.Errorf("failed to read document %d: %s", docs+1, err)
+		}
+		n.add(opts.applyTo(n.path, v))
+		docs++
+	}
+}
+
+// DraftSchema is the JSON Schema dialect written by SampleSchema
+const DraftSchema = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaOptions controls how SampleSchema describes its samples
+type SchemaOptions struct {
+	// JSONSchema writes a JSON Schema instead of the types
+	// found at each path
+	JSONSchema bool
+
+	// Required is the fraction of objects that a key must be found in
+	// for the JSON Schema to require it; e.g. 1 for every object
+	Required float64
+
+	// Sort writes the types found at each path sorted by path instead
+	// of in the order the paths were first found
+	Sort bool
+}
+
+// SampleSchema reads every document of each input as a sample, such as
+// each line of newline delimited JSON, and writes each distinct path found
+// in the samples once, with the indexes of arrays collapsed into [*], along
+// with the types of the values found there and how often; or a JSON Schema
+// that the samples all match. The samples are read and changed as gronOpts
+// says, so redacted values are only ever strings.
+// E.g:
+//
+//	json.users[*].email = string (98/100, null 2/100);
+func SampleSchema(
+	rs []io.Reader,
+	formats []InputFormat,
+	w io.Writer,
+	conv StatementConv,
+	colorize bool,
+	opts SchemaOptions,
+	gronOpts GronOptions,
+) (int, error) {
+	root := newSchemaNode(Statement{{"json", TypBare}}, nil, false)
+	docs := 0
+	for i, r := range rs {
+		n, err := root.addDocuments(makeDecoder(r, formats[i], false, gronOpts), gronOpts)
+		if err != nil {
+			return exitReadInput, errors.Wrapf(err, "failed to read input %d", i+1)
+		}
+		docs += n
+	}
+	if docs == 0 {
+		return exitReadInput, errors.New("no documents were read")
+	}
+
+	if !opts.JSONSchema {
+		ss := root.statements()
+		if opts.Sort {
+			sort.Sort(ss)
+		}
+		for _, s := range ss {
+			fmt.Fprintln(w, conv(s))
+		}
+		return exitOK, nil
+	}
+
+	schema := append(json.OrderedObject{{Key: "$schema", Value: DraftSchema}}, root.jsonSchema(opts.Required)...)
+	err := writeJSON(w, schema, colorize, UngronOptions{})
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write JSON Schema")
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,107 @@
+package gron
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+func TestSampleSchemaTypes(t *testing.T) {
+	cases := []struct {
+		in   string
+		sort bool
+		want []string
+	}{
+		{
+			`{"users":[{"id":1,"email":"a@x","tags":["a"]},{"id":2,"email":null},{"id":3,"email":"c@x","tags":[]}]}`,
+			false,
+			[]string{
+				`json = object (1/1);`,
+				`json.users = array (1/1);`,
+				`json.users[*] = object (3/3);`,
+				`json.users[*].id = number (3/3);`,
+				`json.users[*].email = string (2/3, null 1/3);`,
+				`json.users[*].tags = array (2/3);`,
+				`json.users[*].tags[*] = string (1/1);`,
+			},
+		},
+		{
+			"{\"a\":1}\n{\"a\":\"x\",\"b\":true}\n[]\n",
+			false,
+			[]string{
+				`json = object (2/3, array 1/3);`,
+				`json.a = number (1/2, string 1/2);`,
+				`json.b = boolean (1/2);`,
+			},
+		},
+		{
+			`{"b":1,"a":[true]}`,
+			true,
+			[]string{
+				`json = object (1/1);`,
+				`json.a = array (1/1);`,
+				`json.a[*] = boolean (1/1);`,
+				`json.b = number (1/1);`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := SampleSchema([]io.Reader{strings.NewReader(c.in)}, []InputFormat{InputJSON}, out, StatementToString, false, SchemaOptions{Sort: c.sort}, GronOptions{})
+		if code != exitOK || err != nil {
+			t.Fatalf("want exitOK and nil error; have %d and %s", code, err)
+		}
+
+		have := strings.TrimSpace(out.String())
+		if have != strings.Join(c.want, "\n") {
+			t.Errorf("want:\n%s\nhave:\n%s", strings.Join(c.want, "\n"), have)
+		}
+	}
+}
+
+func TestSampleSchemaJSONSchema(t *testing.T) {
+	samples := []string{
+		"{\"id\":1,\"name\":\"a\",\"tags\":[\"x\"]}\n{\"id\":2,\"name\":null,\"score\":1.5}\n",
+		`{"id":3,"name":"c","score":2}`,
+	}
+	cases := []struct {
+		required float64
+		want     string
+	}{
+		{1, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"id":{"type":"integer"},"name":{"type":["string","null"]},"tags":{"type":"array","items":{"type":"string"}},"score":{"type":"number"}},"required":["id","name"]}`},
+		{0.5, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"id":{"type":"integer"},"name":{"type":["string","null"]},"tags":{"type":"array","items":{"type":"string"}},"score":{"type":"number"}},"required":["id","name","score"]}`},
+	}
+
+	for _, c := range cases {
+		rs := make([]io.Reader, len(samples))
+		for i, s := range samples {
+			rs[i] = strings.NewReader(s)
+		}
+
+		out := &bytes.Buffer{}
+		code, err := SampleSchema(rs, []InputFormat{InputJSON, InputJSON}, out, StatementToString, false, SchemaOptions{JSONSchema: true, Required: c.required}, GronOptions{})
+		if code != exitOK || err != nil {
+			t.Fatalf("want exitOK and nil error; have %d and %s", code, err)
+		}
+
+		compact := &bytes.Buffer{}
+		err = json.Compact(compact, out.Bytes())
+		if err != nil {
+			t.Fatalf("want valid JSON; have %s", err)
+		}
+		if compact.String() != c.want {
+			t.Errorf("want %s for required %g; have %s", c.want, c.required, compact)
+		}
+	}
+}
+
+func TestSampleSchemaNoDocuments(t *testing.T) {
+	_, err := SampleSchema([]io.Reader{strings.NewReader("")}, []InputFormat{InputJSON}, io.Discard, StatementToString, false, SchemaOptions{}, GronOptions{})
+	if err == nil {
+		t.Errorf("want error for input without documents")
+	}
+}
@@ -0,0 +1,243 @@
+package gron
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+// A SparseMode decides how ungron handles arrays whose statements
+// don't cover every index, such as after grepping for json[57]
+type SparseMode int
+
+const (
+	// SparsePad fills the missing elements with null, keeping the
+	// surviving elements at their original indexes
+	SparsePad SparseMode = iota
+
+	// SparseCompact renumbers the surviving elements from zero,
+	// keeping their order
+	SparseCompact
+
+	// SparseObject turns sparse arrays into objects keyed by the
+	// original indexes; e.g. {"57": {...}}
+	SparseObject
+)
+
+// SparseModeFromString returns the SparseMode for a name as
+// accepted on the command line
+func SparseModeFromString(name string) (SparseMode, error) {
+	switch strings.ToLower(name) {
+	case "", "pad", "null":
+		return SparsePad, nil
+	case "compact":
+		return SparseCompact, nil
+	case "object":
+		return SparseObject, nil
+	default:
+		return SparsePad, fmt.Errorf("unknown sparse array mode `%s`", name)
+	}
+}
+
+// DefaultMaxIndex is the largest number of missing elements that ungron
+// fills with null to reach an array index unless told otherwise, so that
+// a statement like json[999999999] = 1; can't allocate gigabytes of
+// memory. Indexes that extend an array by one are always allowed
+const DefaultMaxIndex = 100000
+
+// checkIndex returns an error if setting an index of an array with length
+// elements would leave more than maxIndex missing elements before it.
+// A maxIndex of zero means DefaultMaxIndex and a negative one no limit
+func checkIndex(index, length, maxIndex int) error {
+	if maxIndex == 0 {
+		maxIndex = DefaultMaxIndex
+	}
+	if maxIndex >= 0 && index-length > maxIndex {
+		return fmt.Errorf("array index %d is more than %d past the end of an array of %d elements", index, maxIndex, length)
+	}
+	return nil
+}
+
+// checkPathIndexes returns an error if setting the value at the path
+// keys in v would leave more than maxIndex missing elements before an
+// index of any array along the path, as for checkIndex
+func checkPathIndexes(v interface{}, keys []interface{}, maxIndex int) error {
+	for _, k := range keys {
+		switch kk := k.(type) {
+		case int:
+			arr, _ := v.([]interface{})
+			if err := checkIndex(kk, len(arr), maxIndex); err != nil {
+				return err
+			}
+			v = nil
+			if kk < len(arr) {
+				v = arr[kk]
+			}
+		case string:
+			v, _ = objectGet(v, kk)
+		}
+	}
+	return nil
+}
+
+// statementKeys returns the keys of a statement's path starting with the
+// leading bare word, along with the position of the token for each key.
+// The path of a deletion statement follows the delete keyword
+func statementKeys(s Statement) ([]interface{}, []int, error) {
+	var keys []interface{}
+	var positions []int
+	for i, t := range s {
+		switch t.Typ {
+		case TypBare:
+			keys = append(keys, t.Text)
+		case TypQuotedKey:
+			var k string
+			err := json.Unmarshal([]byte(t.Text), &k)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid quoted key `%s`", t.Text)
+			}
+			keys = append(keys, k)
+		case TypNumericKey:
+			k, err := strconv.Atoi(t.Text)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid integer key `%s`", t.Text)
+			}
+			keys = append(keys, k)
+		case TypEquals, TypSemi:
+			return keys, positions, nil
+		default:
+			continue
+		}
+		positions = append(positions, i)
+	}
+	return keys, positions, nil
+}
+
+// WithSparseArrays returns a copy of the statements with the indexes
+// of sparse arrays rewritten according to the mode, ready for
+// ToInterface, which fills any that are left with null
+func (ss Statements) WithSparseArrays(mode SparseMode) Statements {
+	if mode == SparsePad {
+		return ss
+	}
+
+	// Find the indexes used for each array, identified by its
+	// JSON Pointer before any renumbering
+	indexes := make(map[string]map[int]bool)
+	for _, s := range ss {
+		keys, _, err := statementKeys(s)
+		if err != nil {
+			// Left for ToInterface to report
+			continue
+		}
+		for j, k := range keys {
+			index, ok := k.(int)
+			if !ok {
+				continue
+			}
+			p := jsonPointer(keys[:j])
+			if indexes[p] == nil {
+				indexes[p] = make(map[int]bool)
+			}
+			indexes[p][index] = true
+		}
+	}
+	// Number the distinct indexes of each array in order, noting the
+	// arrays that are sparse: those whose indexes aren't 0 to n-1
+	renumber := make(map[string]map[int]int, len(indexes))
+	sparse := make(map[string]bool)
+	for p, set := range indexes {
+		is := make([]int, 0, len(set))
+		for index := range set {
+			is = append(is, index)
+		}
+		sort.Ints(is)
+
+		renumber[p] = make(map[int]int, len(is))
+		for n, index := range is {
+			renumber[p][index] = n
+		}
+		sparse[p] = is[len(is)-1] != len(is)-1
+	}
+
+	out := make(Statements, len(ss))
+	for i, s := range ss {
+		keys, positions, err := statementKeys(s)
+		if err != nil {
+			out[i] = s
+			continue
+		}
+
+		rewritten := make(Statement, len(s))
+		copy(rewritten, s)
+		for j, k := range keys {
+			index, ok := k.(int)
+			if !ok {
+				continue
+			}
+			p := jsonPointer(keys[:j])
+			switch {
+			case mode == SparseCompact:
+				rewritten[positions[j]] = Token{strconv.Itoa(renumber[p][index]), TypNumericKey}
+			case sparse[p]:
+				rewritten[positions[j]] = Token{quoteString(strconv.Itoa(index)), TypQuotedKey}
+			}
+		}
+
+		// Sparse arrays that become objects need to be assigned as objects
+		last := len(rewritten.withoutComment()) - 2
+		if mode == SparseObject && last > 0 && rewritten[last].Typ == TypEmptyArray && sparse[jsonPointer(keys)] {
+			rewritten[last] = Token{"{}", TypEmptyObject}
+		}
+		out[i] = rewritten
+	}
+	return out
+}
+
+// withRootIndexesCompacted returns a copy of the statements with the
+// indexes of a top level array renumbered from zero, so that elements
+// filtered out of a stream don't leave holes; e.g. json[2] becomes
+// json[0] if no other element of the array is assigned
+func (ss Statements) withRootIndexesCompacted() Statements {
+	set := make(map[int]bool)
+	for _, s := range ss {
+		keys, _, err := statementKeys(s)
+		if err != nil || len(keys) < 2 {
+			continue
+		}
+		if index, ok := keys[1].(int); ok {
+			set[index] = true
+		}
+	}
+
+	is := make([]int, 0, len(set))
+	for index := range set {
+		is = append(is, index)
+	}
+	sort.Ints(is)
+	renumber := make(map[int]int, len(is))
+	for n, index := range is {
+		renumber[index] = n
+	}
+
+	out := make(Statements, len(ss))
+	for i, s := range ss {
+		out[i] = s
+		keys, positions, err := statementKeys(s)
+		if err != nil || len(keys) < 2 {
+			continue
+		}
+		index, ok := keys[1].(int)
+		if !ok || renumber[index] == index {
+			continue
+		}
+		rewritten := make(Statement, len(s))
+		copy(rewritten, s)
+		rewritten[positions[1]] = Token{strconv.Itoa(renumber[index]), TypNumericKey}
+		out[i] = rewritten
+	}
+	return out
+}
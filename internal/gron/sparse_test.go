@@ -0,0 +1,106 @@
+package gron
+
+import (
+	"fmt"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+func TestWithSparseArrays(t *testing.T) {
+	in := []string{
+		`json = {};`,
+		`json.a = [];`,
+		`json.a[3] = "x";`,
+		`json.a[57] = {};`,
+		`json.a[57].b = [];`,
+		`json.a[57].b[0] = 1;`,
+		`json.a[57].b[1] = 2;`,
+		`json.c = [];`,
+		`json.c[0] = true;`,
+	}
+
+	cases := []struct {
+		mode SparseMode
+		want string
+	}{
+		{SparseCompact, `{"a":["x",{"b":[1,2]}],"c":[true]}`},
+		{SparseObject, `{"a":{"3":"x","57":{"b":[1,2]}},"c":[true]}`},
+	}
+
+	for _, c := range cases {
+		ss := statementsFromStringSlice(in).WithSparseArrays(c.mode)
+
+		merged, err := ss.ToInterface()
+		if err != nil {
+			t.Fatalf("want nil error for mode %d; have %s", c.mode, err)
+		}
+
+		have, err := json.Marshal(unwrapRoot(merged))
+		if err != nil {
+			t.Fatalf("failed to marshal ungronned value: %s", err)
+		}
+		if string(have) != c.want {
+			t.Errorf("want %s for mode %d; have %s", c.want, c.mode, have)
+		}
+	}
+}
+
+func TestWithSparseArraysMaxIndex(t *testing.T) {
+	in := statementsFromStringSlice([]string{`json = [];`, `json[0] = 1;`, `json[1002] = 2;`})
+
+	_, _, err := in.WithSparseArrays(SparsePad).ToInterfaceWithConflicts(ConflictLastWins, 1000)
+	se, ok := err.(*StatementError)
+	if !ok {
+		t.Fatalf("want *StatementError; have %#v", err)
+	}
+	if se.Line != 3 {
+		t.Errorf("want error on line 3; have line %d", se.Line)
+	}
+
+	for _, mode := range []SparseMode{SparseCompact, SparseObject} {
+		_, _, err = in.WithSparseArrays(mode).ToInterfaceWithConflicts(ConflictLastWins, 1000)
+		if err != nil {
+			t.Errorf("want nil error for mode %d; have %s", mode, err)
+		}
+	}
+
+	_, _, err = in.ToInterfaceWithConflicts(ConflictLastWins, -1)
+	if err != nil {
+		t.Errorf("want nil error without a limit; have %s", err)
+	}
+}
+
+func TestToInterfaceMaxIndex(t *testing.T) {
+	in := statementsFromStringSlice([]string{`json = [];`, `json[0] = 1;`, `json[999999999] = 2;`})
+
+	_, err := in.ToInterface()
+	se, ok := err.(*StatementError)
+	if !ok {
+		t.Fatalf("want *StatementError; have %#v", err)
+	}
+	if se.Line != 3 {
+		t.Errorf("want error on line 3; have line %d", se.Line)
+	}
+
+	_, _, err = in.ToInterfaceWithConflicts(ConflictLastWins, 1000)
+	if err == nil {
+		t.Errorf("want non-nil error for an index larger than the limit; have nil")
+	}
+}
+
+func TestToInterfaceMaxIndexDense(t *testing.T) {
+	in := Statements{StatementFromString(`json = [];`)}
+	for i := 0; i <= 2000; i++ {
+		in = append(in, StatementFromString(fmt.Sprintf("json[%d] = %d;", i, i)))
+	}
+
+	merged, _, err := in.ToInterfaceWithConflicts(ConflictLastWins, 1000)
+	if err != nil {
+		t.Fatalf("want nil error for indexes that extend the array by one; have %s", err)
+	}
+	arr, ok := unwrapRoot(merged).([]interface{})
+	if !ok || len(arr) != 2001 {
+		t.Errorf("want an array of 2001 elements; have %#v", unwrapRoot(merged))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	json "github.com/virtuald/go-ordered-json"
 
@@ -73,6 +74,7 @@ func (s Statement) Jsonify() (Statement, error) {
 	// long. So len(s)+1 ≥ 2*m+5 = len(j). Therefore an initaial
 	// allocation of j with capacity len(s)+1 will allow us to carry
 	// through without reallocation.
+	s = s.withoutComment()
 	j := make(Statement, 0, len(s)+1)
 	if len(s) < 4 || s[0].Typ != TypBare || s[len(s)-3].Typ != TypEquals ||
 		s[len(s)-1].Typ != TypSemi {
@@ -102,6 +104,61 @@ func (s Statement) Jsonify() (Statement, error) {
 	return j, nil
 }
 
+// splitAssignment splits an assignment statement into the
+// tokens making up its path and the token holding its value
+// E.g. json.foo = 1; -> json.foo, 1
+func (s Statement) splitAssignment() (Statement, Token, bool) {
+	s = s.withoutComment()
+	if len(s) < 4 || s[0].Typ != TypBare || s[len(s)-3].Typ != TypEquals ||
+		s[len(s)-1].Typ != TypSemi {
+		return nil, Token{}, false
+	}
+	return s[:len(s)-3], s[len(s)-2], true
+}
+
+// withoutComment returns the statement without its comment, if it has one
+func (s Statement) withoutComment() Statement {
+	if len(s) > 0 && s[len(s)-1].Typ == TypComment {
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// comment returns the text of the statement's comment without
+// the leading slashes, or an empty string if it has none
+func (s Statement) comment() string {
+	if len(s) == 0 || s[len(s)-1].Typ != TypComment {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(s[len(s)-1].Text, "//"))
+}
+
+// commentParts returns the comma separated parts of the statement's
+// comment; e.g. embedded JSON and !Ref for // embedded JSON, !Ref
+func (s Statement) commentParts() []string {
+	comment := s.comment()
+	if comment == "" {
+		return nil
+	}
+	parts := strings.Split(comment, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// withComment returns a copy of a statement with text added to its
+// comment, after any parts the comment already has
+func (s Statement) withComment(text string) Statement {
+	if existing := s.comment(); existing != "" {
+		text = existing + ", " + text
+	}
+	s = s.withoutComment()
+	new := make(Statement, len(s), len(s)+1)
+	copy(new, s)
+	return append(new, Token{"// " + text, TypComment})
+}
+
 // withQuotedKey returns a copy of a statement with a new
 // quoted key token appended to it
 func (s Statement) withQuotedKey(k string) Statement {
@@ -115,6 +172,15 @@ func (s Statement) withQuotedKey(k string) Statement {
 	)
 }
 
+// withKey returns a copy of a statement with an object key appended
+// to it, as a bare word if it's a valid identifier or quoted if not
+func (s Statement) withKey(k string) Statement {
+	if validIdentifier(k) {
+		return s.withBare(k)
+	}
+	return s.withQuotedKey(k)
+}
+
 // withNumericKey returns a copy of a statement with a new
 // numeric key token appended to it
 func (s Statement) withNumericKey(k int) Statement {
@@ -128,6 +194,19 @@ func (s Statement) withNumericKey(k int) Statement {
 	)
 }
 
+// withWildcardKey returns a copy of a statement with a key
+// standing for any array index appended to it; e.g. json.users[*]
+func (s Statement) withWildcardKey() Statement {
+	new := make(Statement, len(s), len(s)+3)
+	copy(new, s)
+	return append(
+		new,
+		Token{"[", TypLBrace},
+		Token{"*", TypWildcard},
+		Token{"]", TypRBrace},
+	)
+}
+
 // Statements is a list of assignment Statements.
 // E.g statement: json.foo = "bar";
 type Statements []Statement
@@ -271,38 +350,120 @@ out:
 	return s, nil
 }
 
-// ungron turns statements into a proper datastructure
+// ungron turns statements into a proper datastructure. Deletion
+// statements remove values from the statements merged before them.
+// Errors are returned as a *StatementError
 func (ss Statements) ToInterface() (interface{}, error) {
-	// Get all the individually parsed statements
-	var parsed []interface{}
-	for _, s := range ss {
-		u, err := ungronTokens(s)
+	merged, _, err := ss.ToInterfaceWithConflicts(ConflictLastWins, DefaultMaxIndex)
+	return merged, err
+}
 
-		switch err.(type) {
-		case nil:
-			// no problem :)
-		case errRecoverable:
+// ToInterfaceWithConflicts turns statements into a proper datastructure,
+// also returning every statement that conflicts with an earlier one.
+// The policy decides what happens to a conflicting statement: with
+// ConflictLastWins it replaces scalar values, although replacing an
+// object or array with a different type of value is an error; with
+// ConflictFirstWins it's skipped; and with ConflictError it's an error.
+// Array indexes that would leave more than maxIndex missing elements
+// are an error, as for checkIndex. Errors are returned as a *StatementError
+func (ss Statements) ToInterfaceWithConflicts(policy ConflictPolicy, maxIndex int) (interface{}, []AssignmentConflict, error) {
+	var merged interface{}
+	var conflicts []AssignmentConflict
+	tracker := newAssignmentTracker()
+	parsed := 0
+	for i, s := range ss {
+		if s.isDeletion() {
+			keys, err := s.deletionKeys()
+			if err != nil {
+				return nil, conflicts, &StatementError{Line: i + 1, Err: errors.Wrapf(err, "ungron failed for `%s`", s)}
+			}
+			merged = deletePath(merged, keys)
+			tracker.forget(keys)
 			continue
-		default:
-			return nil, errors.Wrapf(err, "ungron failed for `%s`", s)
 		}
 
-		parsed = append(parsed, u)
-	}
+		if len(s) == 0 || s[0].Typ == TypIgnored {
+			continue
+		}
 
-	if len(parsed) == 0 {
-		return nil, fmt.Errorf("no statements were parsed")
-	}
+		keys, value, err := s.rootedPath()
+		if err != nil {
+			return nil, conflicts, &StatementError{Line: i + 1, Err: errors.Wrapf(err, "ungron failed for `%s`", s)}
+		}
+		found := tracker.check(i+1, keys, value)
+		conflicts = append(conflicts, found...)
+		if len(found) > 0 && policy == ConflictFirstWins {
+			continue
+		}
+		for _, c := range found {
+			if policy == ConflictError || c.replacesContainer {
+				return nil, conflicts, &StatementError{Line: i + 1, Err: c}
+			}
+		}
+		tracker.record(i+1, keys, value)
 
-	merged := parsed[0]
-	for _, p := range parsed[1:] {
-		m, err := recursiveMerge(merged, p)
+		m, err := ungronInto(merged, s, maxIndex)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to merge statements")
+			return nil, conflicts, &StatementError{Line: i + 1, Err: errors.Wrapf(err, "ungron failed for `%s`", s)}
 		}
 		merged = m
+		parsed++
 	}
-	return merged, nil
+
+	if parsed == 0 {
+		return nil, conflicts, fmt.Errorf("no statements were parsed")
+	}
+	return merged, conflicts, nil
+}
+
+// rootedPath returns the keys of an assignment's path, starting with
+// the leading bare word, and the value assigned
+func (s Statement) rootedPath() ([]interface{}, Token, error) {
+	path, value, ok := s.splitAssignment()
+	if !ok {
+		return nil, Token{}, errors.New("statement is not an assignment")
+	}
+	keys, err := path.Path()
+	if err != nil {
+		return nil, Token{}, err
+	}
+	return append([]interface{}{path[0].Text}, keys...), value, nil
+}
+
+// isDeletion returns true for statements that remove a value
+// rather than assign one
+// E.g:
+//
+//	delete json.foo;
+//	json.foo = undefined;
+func (s Statement) isDeletion() bool {
+	s = s.withoutComment()
+	if len(s) > 0 && s[0].Typ == TypDelete {
+		return true
+	}
+	if len(s) < 3 || s[len(s)-1].Typ != TypSemi {
+		return false
+	}
+	value := s[len(s)-2]
+	return value.Typ == TypUndefined && value.Text == "undefined" && s[len(s)-3].Typ == TypEquals
+}
+
+// deletionKeys returns the keys of the path removed by a deletion
+// statement, starting with the leading bare word
+func (s Statement) deletionKeys() ([]interface{}, error) {
+	path := s.withoutComment()
+	if path[0].Typ == TypDelete {
+		path = path[1:]
+		if len(path) > 0 && path[len(path)-1].Typ == TypSemi {
+			path = path[:len(path)-1]
+		}
+	}
+
+	keys, err := path.Path()
+	if err != nil {
+		return nil, err
+	}
+	return append([]interface{}{path[0].Text}, keys...), nil
 }
 
 // Less compares two statements for sort.Sort
@@ -382,32 +543,216 @@ func (ss Statements) Contains(search Statement) bool {
 // StatementsFromJSON takes an io.Reader containing JSON
 // and returns statements or an error on failure
 func StatementsFromJSON(r Decoder, prefix Statement) (Statements, error) {
+	return statementsFromDecoder(r, prefix, GronOptions{})
+}
+
+// statementsFromDecoder decodes a value and returns its statements,
+// applying the options
+func statementsFromDecoder(r Decoder, prefix Statement, opts GronOptions) (Statements, error) {
 	var top interface{}
 	err := r.Decode(&top)
 	if err != nil {
 		return nil, err
 	}
 	ss := make(Statements, 0, 32)
-	ss.fill(prefix, top)
+	ss.fillWith(prefix, top, opts)
+
+	if yd, ok := r.(*yamlDecoder); ok && opts.YAMLTags {
+		ss.annotateTags(len(prefix), yd.tags)
+	}
 	return ss, nil
 }
 
+// yamlTags returns the YAML tags given in the comments of assignment
+// statements, as written by annotateTags
+func (ss Statements) yamlTags() *yamlTags {
+	tags := newYAMLTags()
+	for _, s := range ss {
+		parts := s.commentParts()
+		if len(parts) == 0 {
+			continue
+		}
+		path, _, ok := s.splitAssignment()
+		if !ok {
+			continue
+		}
+		keys, err := path.Path()
+		if err != nil {
+			continue
+		}
+		p := jsonPointer(keys)
+		for _, part := range parts {
+			switch {
+			case strings.HasPrefix(part, "key !"):
+				tags.keys[p] = strings.TrimPrefix(part, "key ")
+			case strings.HasPrefix(part, "!") && !strings.ContainsAny(part, " \t"):
+				tags.values[p] = part
+			}
+		}
+	}
+	return tags
+}
+
+// annotateTags adds a comment holding the YAML tags to each statement
+// with tags, found by the JSON Pointer of its path after the prefix.
+// E.g:
+//
+//	json.data = "aGk="; // !!binary
+//	json["1"] = "one"; // key !!int
+//	json["2"] = "aGk="; // key !!int, !!binary
+func (ss Statements) annotateTags(prefixLen int, tags *yamlTags) {
+	if tags.empty() {
+		return
+	}
+	for i, s := range ss {
+		path, _, ok := s.splitAssignment()
+		if !ok {
+			continue
+		}
+		keys, err := Statement(append(Statement{path[0]}, path[prefixLen:]...)).Path()
+		if err != nil {
+			continue
+		}
+
+		p := jsonPointer(keys)
+		var parts []string
+		if tag, ok := tags.keys[p]; ok {
+			parts = append(parts, "key "+tag)
+		}
+		if tag, ok := tags.values[p]; ok {
+			parts = append(parts, tag)
+		}
+		if len(parts) > 0 {
+			ss[i] = s.withComment(strings.Join(parts, ", "))
+		}
+	}
+}
+
 // fill takes a prefix statement and some value and recursively fills
 // the statement list using that value
 func (ss *Statements) fill(prefix Statement, v interface{}) {
+	ss.fillWith(prefix, v, GronOptions{})
+}
+
+// fillWith is like fill, applying the options to the values
+func (ss *Statements) fillWith(prefix Statement, v interface{}, opts GronOptions) {
+	// Only scalars are redacted so that redacted values keep their shape
+	if opts.Redact != nil {
+		typ := valueTokenFromInterface(v).Typ
+		if typ != TypEmptyObject && typ != TypEmptyArray && opts.Redact.redacts(prefix) {
+			ss.AddWithValue(prefix, Token{quoteString(RedactedValue), TypString})
+			return
+		}
+	}
+
+	embedded := false
+	if s, ok := v.(string); ok && opts.EmbeddedJSON {
+		v, embedded = decodeEmbeddedJSON(s)
+		if !embedded {
+			v = s
+		}
+	}
+	if s, ok := v.(string); ok && opts.MaxStringLength > 0 {
+		v = truncateString(s, opts.MaxStringLength)
+	}
+
 	// Add a statement for the current prefix and value
 	ss.AddWithValue(prefix, valueTokenFromInterface(v))
+	last := len(*ss) - 1
+	if embedded {
+		(*ss)[last] = (*ss)[last].withComment(embeddedJSONComment)
+	}
+	arr, ok := v.([]interface{})
+	if !ok || opts.MaxArray <= 0 || len(arr) <= opts.MaxArray {
+		ss.fillMembers(prefix, v, opts)
+		return
+	}
+
+	// The summary of what was left out of a shortened array is added
+	// to the array's own statement so that it stays with the array
+	// however the statements are sorted
+	omitted := fmt.Sprintf("%d of %d elements omitted", len(arr)-opts.MaxArray, len(arr))
+	(*ss)[last] = (*ss)[last].withComment(omitted)
+	ss.fillMembers(prefix, arr[:opts.MaxArray], opts)
+}
+
+// changesValues returns true if the options change any values, so
+// that applyTo has work to do
+func (opts GronOptions) changesValues() bool {
+	return opts.Redact != nil || opts.EmbeddedJSON || opts.MaxArray > 0 || opts.MaxStringLength > 0
+}
+
+// applyTo returns a value at a path with the options applied the same
+// way that fillWith applies them, for output modes that write values
+// rather than statements; e.g. tables and type definitions
+func (opts GronOptions) applyTo(prefix Statement, v interface{}) interface{} {
+	if !opts.changesValues() {
+		return v
+	}
+
+	if opts.Redact != nil {
+		typ := valueTokenFromInterface(v).Typ
+		if typ != TypEmptyObject && typ != TypEmptyArray && opts.Redact.redacts(prefix) {
+			return RedactedValue
+		}
+	}
+	if s, ok := v.(string); ok && opts.EmbeddedJSON {
+		if embedded, ok := decodeEmbeddedJSON(s); ok {
+			v = embedded
+		}
+	}
+	if s, ok := v.(string); ok && opts.MaxStringLength > 0 {
+		v = truncateString(s, opts.MaxStringLength)
+	}
+
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		out := make(json.OrderedObject, len(vv))
+		for i, m := range vv {
+			out[i] = json.Member{Key: m.Key, Value: opts.applyTo(prefix.withKey(m.Key), m.Value)}
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(vv))
+		for k, sub := range vv {
+			out[k] = opts.applyTo(prefix.withKey(k), sub)
+		}
+		return out
+	case []interface{}:
+		if opts.MaxArray > 0 && len(vv) > opts.MaxArray {
+			vv = vv[:opts.MaxArray]
+		}
+		out := make([]interface{}, len(vv))
+		for i, e := range vv {
+			out[i] = opts.applyTo(prefix.withNumericKey(i), e)
+		}
+		return out
+	}
+	return v
+}
+
+// truncateString returns a string cut down to a number of runes,
+// ending with an ellipsis if anything was cut
+func truncateString(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max]) + "…"
+}
 
-	// Recurse into objects and arrays
+// fillMembers fills the statement list with the members of
+// an object or the elements of an array
+func (ss *Statements) fillMembers(prefix Statement, v interface{}, opts GronOptions) {
 	switch vv := v.(type) {
 
 	case json.OrderedObject:
 		// It's an object
 		for _, member := range vv {
 			if validIdentifier(member.Key) {
-				ss.fill(prefix.withBare(member.Key), member.Value)
+				ss.fillWith(prefix.withBare(member.Key), member.Value, opts)
 			} else {
-				ss.fill(prefix.withQuotedKey(member.Key), member.Value)
+				ss.fillWith(prefix.withQuotedKey(member.Key), member.Value, opts)
 			}
 		}
 
@@ -416,25 +761,25 @@ func (ss *Statements) fill(prefix Statement, v interface{}) {
 		for k, sub := range vv {
 			ks := fmt.Sprintf("%v", k)
 			if validIdentifier(ks) {
-				ss.fill(prefix.withBare(ks), sub)
+				ss.fillWith(prefix.withBare(ks), sub, opts)
 			} else {
-				ss.fill(prefix.withQuotedKey(ks), sub)
+				ss.fillWith(prefix.withQuotedKey(ks), sub, opts)
 			}
 		}
 	case map[string]interface{}:
 		// It's an object
 		for k, sub := range vv {
 			if validIdentifier(k) {
-				ss.fill(prefix.withBare(k), sub)
+				ss.fillWith(prefix.withBare(k), sub, opts)
 			} else {
-				ss.fill(prefix.withQuotedKey(k), sub)
+				ss.fillWith(prefix.withQuotedKey(k), sub, opts)
 			}
 		}
 
 	case []interface{}:
 		// It's an array
 		for k, sub := range vv {
-			ss.fill(prefix.withNumericKey(k), sub)
+			ss.fillWith(prefix.withNumericKey(k), sub, opts)
 		}
 	}
 }
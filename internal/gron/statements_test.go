@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	json "github.com/virtuald/go-ordered-json"
@@ -33,7 +34,7 @@ func TestStatementsSimple(t *testing.T) {
 		"": 2
 	}`)
 
-	ss, err := StatementsFromJSON(MakeDecoder(bytes.NewReader(j), false, false), Statement{{"json", TypBare}})
+	ss, err := StatementsFromJSON(MakeDecoder(bytes.NewReader(j), InputJSON, false), Statement{{"json", TypBare}})
 	if err != nil {
 		t.Errorf("Want nil error from makeStatementsFromJSON() but got %s", err)
 	}
@@ -63,6 +64,61 @@ func TestStatementsSimple(t *testing.T) {
 	}
 }
 
+func TestStatementsLimits(t *testing.T) {
+	j := []byte(`{"items":[1,2,3,4,5],"short":"abc","long":"héllo world","nested":[["a","b"],[]]}`)
+
+	opts := GronOptions{MaxArray: 1, MaxStringLength: 4}
+	ss, err := statementsFromDecoder(MakeDecoder(bytes.NewReader(j), InputJSON, false), Statement{{"json", TypBare}}, opts)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	want := []string{
+		`json = {};`,
+		`json.items = []; // 4 of 5 elements omitted`,
+		`json.items[0] = 1;`,
+		`json.short = "abc";`,
+		`json.long = "héll…";`,
+		`json.nested = []; // 1 of 2 elements omitted`,
+		`json.nested[0] = []; // 1 of 2 elements omitted`,
+		`json.nested[0][0] = "a";`,
+	}
+	if len(ss) != len(want) {
+		t.Fatalf("want %d statements; have %d: %s", len(want), len(ss), ss)
+	}
+	for i, s := range ss {
+		if s.String() != want[i] {
+			t.Errorf("want `%s` for statement %d; have `%s`", want[i], i, s)
+		}
+	}
+}
+
+func TestGronLimitsSorted(t *testing.T) {
+	in := `{"arr":[{"b":1,"a":[1,2,3]},{"b":2,"a":[4,5,6]},{"b":3}]}`
+	want := `json = {};
+json.arr = []; // 1 of 3 elements omitted
+json.arr[0] = {};
+json.arr[0].a = []; // 1 of 3 elements omitted
+json.arr[0].a[0] = 1;
+json.arr[0].a[1] = 2;
+json.arr[0].b = 1;
+json.arr[1] = {};
+json.arr[1].a = []; // 1 of 3 elements omitted
+json.arr[1].a[0] = 4;
+json.arr[1].a[1] = 5;
+json.arr[1].b = 2;
+`
+
+	out := &bytes.Buffer{}
+	_, err := Gron(strings.NewReader(in), out, StatementToString, InputJSON, true, false, GronOptions{MaxArray: 2})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if out.String() != want {
+		t.Errorf("want:\n%s\nhave:\n%s", want, out.String())
+	}
+}
+
 func TestStatementsSimpleYaml(t *testing.T) {
 	j := []byte(`'': 2
 a quoted: value
@@ -80,7 +136,7 @@ x: |
   y: "z"
 id: 66912849`)
 
-	ss, err := StatementsFromJSON(MakeDecoder(bytes.NewReader(j), true, false), Statement{{"yaml", TypBare}})
+	ss, err := StatementsFromJSON(MakeDecoder(bytes.NewReader(j), InputYAML, false), Statement{{"yaml", TypBare}})
 	if err != nil {
 		t.Errorf("Want nil error from makeStatementsFromJSON() but got %s", err)
 	}
@@ -226,11 +282,58 @@ func TestUngronStatementsSimple(t *testing.T) {
 	}
 }
 
+func TestUngronStatementsDeletion(t *testing.T) {
+	in := statementsFromStringSlice([]string{
+		`json.contact = {};`,
+		`json.contact.twitter = "@TomNomNom";`,
+		`json.contact.email = "mail@tomnomnom.com";`,
+		`json.contact["a\"b"] = 1;`,
+		`json.likes = [];`,
+		`json.likes[0] = "code";`,
+		`json.likes[1] = "cheese";`,
+		`json.likes[2] = "meat";`,
+		`delete json.contact.twitter;`,
+		`delete json.contact["a\"b"];`,
+		`json.likes[1] = undefined;`,
+		`delete json.missing.key;`,
+		`json.likes[2] = "tea";`,
+	})
+
+	want := json.OrderedObject{
+		{
+			Key: "json", Value: json.OrderedObject{
+				{
+					Key: "contact", Value: json.OrderedObject{
+						{Key: "email", Value: "mail@tomnomnom.com"},
+					},
+				},
+				{
+					Key: "likes", Value: []interface{}{"code", "meat", "tea"},
+				},
+			},
+		},
+	}
+
+	have, err := in.ToInterface()
+	if err != nil {
+		t.Fatalf("want nil error but have: %s", err)
+	}
+
+	t.Logf("Have: %#v", have)
+	t.Logf("Want: %#v", want)
+
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have and want are not equal")
+	}
+}
+
 func TestUngronStatementsInvalid(t *testing.T) {
 	cases := []Statements{
 		statementsFromStringSlice([]string{``}),
 		statementsFromStringSlice([]string{`this isn't a statement at all`}),
 		statementsFromStringSlice([]string{`json[0] = 1;`, `json.bar = 1;`}),
+		statementsFromStringSlice([]string{`json = {};`, `json.a = 1;`, `json.a = u;`}),
+		statementsFromStringSlice([]string{`json = {};`, `json.a = undefinedx;`}),
 	}
 
 	for _, c := range cases {
@@ -241,6 +344,24 @@ func TestUngronStatementsInvalid(t *testing.T) {
 	}
 }
 
+func TestUngronStatementsErrorLine(t *testing.T) {
+	in := statementsFromStringSlice([]string{
+		`json = {};`,
+		``,
+		`json.a = 1;`,
+		`json.b = [;`,
+	})
+
+	_, err := in.ToInterface()
+	se, ok := err.(*StatementError)
+	if !ok {
+		t.Fatalf("want *StatementError; have %#v", err)
+	}
+	if se.Line != 4 {
+		t.Errorf("want error on line 4; have line %d", se.Line)
+	}
+}
+
 func TestStatement(t *testing.T) {
 	s := Statement{
 		Token{"json", TypBare},
@@ -257,3 +378,86 @@ func TestStatement(t *testing.T) {
 		t.Errorf("have: `%s` want: `%s`", have, want)
 	}
 }
+
+func TestUngronStatementsConflicts(t *testing.T) {
+	cases := []struct {
+		in        []string
+		conflicts []string
+		errLine   int
+	}{
+		{[]string{`json = {};`, `json.a = 1;`, `json.a = 1;`}, nil, 0},
+		{[]string{`json = {};`, `json.a = 1;`, `json.a = 2;`}, []string{"line 3: json.a was already assigned a different value on line 2"}, 0},
+		{[]string{`json.a = "x";`, `json.a.b = true;`}, []string{"line 2: json.a was already assigned a different value on line 1"}, 0},
+		{[]string{`json.a = [];`, `json.a = {};`}, []string{"line 2: json.a was already assigned a different value on line 1"}, 2},
+		{[]string{`json[0] = 1;`, `json["a b"] = 1;`}, []string{"line 2: json was already assigned a different value on line 1"}, 2},
+		{[]string{`json.a = {};`, `json.a.b = 1;`, `delete json.a;`, `json.a = 2;`}, nil, 0},
+		{[]string{`json.a = {};`, `json.a = 1;`, `json.a = 2;`}, []string{"line 2: json.a was already assigned a different value on line 1"}, 2},
+	}
+
+	for _, c := range cases {
+		_, conflicts, err := statementsFromStringSlice(c.in).ToInterfaceWithConflicts(ConflictLastWins, 0)
+
+		if c.errLine == 0 && err != nil {
+			t.Errorf("want nil error for %v; have %s", c.in, err)
+		}
+		if c.errLine != 0 {
+			se, ok := err.(*StatementError)
+			if !ok {
+				t.Fatalf("want *StatementError for %v; have %#v", c.in, err)
+			}
+			if se.Line != c.errLine {
+				t.Errorf("want error on line %d for %v; have line %d", c.errLine, c.in, se.Line)
+			}
+		}
+
+		if len(conflicts) != len(c.conflicts) {
+			t.Fatalf("want conflicts %v for %v; have %v", c.conflicts, c.in, conflicts)
+		}
+		for i, conflict := range conflicts {
+			if conflict.String() != c.conflicts[i] {
+				t.Errorf("want conflict `%s`; have `%s`", c.conflicts[i], conflict.String())
+			}
+		}
+	}
+}
+
+func TestUngronStatementsConflictError(t *testing.T) {
+	in := statementsFromStringSlice([]string{`json = {};`, `json.a = 1;`, `json.b = 1;`, `json.a = 2;`})
+
+	_, err := in.ToInterface()
+	if err != nil {
+		t.Errorf("want nil error with ConflictLastWins; have %s", err)
+	}
+
+	_, _, err = in.ToInterfaceWithConflicts(ConflictError, 0)
+	se, ok := err.(*StatementError)
+	if !ok {
+		t.Fatalf("want *StatementError; have %#v", err)
+	}
+	if se.Line != 4 {
+		t.Errorf("want error on line 4; have line %d", se.Line)
+	}
+	if se.Error() != "line 4: json.a was already assigned a different value on line 2" {
+		t.Errorf("unexpected error message `%s`", se)
+	}
+}
+
+func TestUngronStatementsConflictFirstWins(t *testing.T) {
+	in := statementsFromStringSlice([]string{`json = {};`, `json.a = 1;`, `json.a = 2;`, `json.a.b = 3;`, `json.b = [];`, `json.b = "x";`})
+
+	merged, conflicts, err := in.ToInterfaceWithConflicts(ConflictFirstWins, 0)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if len(conflicts) != 3 {
+		t.Errorf("want 3 conflicts; have %v", conflicts)
+	}
+
+	have, err := json.Marshal(unwrapRoot(merged))
+	if err != nil {
+		t.Fatalf("failed to marshal ungronned value: %s", err)
+	}
+	if string(have) != `{"a":1,"b":[]}` {
+		t.Errorf("want the first value of each path; have %s", have)
+	}
+}
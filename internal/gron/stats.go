@@ -0,0 +1,205 @@
+package gron
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+// statsValueTypes are the value token types counted by Stats,
+// in the order they're reported
+var statsValueTypes = []struct {
+	typ  TokenTyp
+	name string
+}{
+	{TypEmptyObject, "objects"},
+	{TypEmptyArray, "arrays"},
+	{TypString, "strings"},
+	{TypNumber, "numbers"},
+	{TypTrue, "true"},
+	{TypFalse, "false"},
+	{TypNull, "null"},
+}
+
+// A sizedValue is a value found at a path along with its size
+type sizedValue struct {
+	path  Statement
+	value interface{}
+	size  int
+}
+
+// docStats holds the statistics gathered about a document
+type docStats struct {
+	statements int
+	maxDepth   int
+	types      map[TokenTyp]int
+
+	arrays  []sizedValue
+	objects []sizedValue
+	strings []sizedValue
+}
+
+// add gathers the statistics for a value at a path and everything
+// beneath it, the same way that fill makes statements for them
+func (st *docStats) add(prefix Statement, depth int, v interface{}) {
+	st.statements++
+	st.types[valueTokenFromInterface(v).Typ]++
+	if depth > st.maxDepth {
+		st.maxDepth = depth
+	}
+
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		st.objects = append(st.objects, sizedValue{prefix, v, len(vv)})
+		for _, m := range vv {
+			st.add(prefix.withKey(m.Key), depth+1, m.Value)
+		}
+	case []interface{}:
+		st.arrays = append(st.arrays, sizedValue{prefix, v, len(vv)})
+		for i, e := range vv {
+			st.add(prefix.withNumericKey(i), depth+1, e)
+		}
+	case string:
+		st.strings = append(st.strings, sizedValue{prefix, v, utf8.RuneCountInString(vv)})
+	}
+}
+
+// largest returns up to top of the values with the largest sizes,
+// keeping document order between values of the same size
+func largest(values []sizedValue, top int) []sizedValue {
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].size > values[j].size
+	})
+	if top > 0 && len(values) > top {
+		return values[:top]
+	}
+	return values
+}
+
+// sizedStatement returns the statement assigning a sized value,
+// with a comment giving its size. Long strings are cut short so
+// that they don't swamp the report
+func sizedStatement(sv sizedValue, unit string) Statement {
+	if s, ok := sv.value.(string); ok {
+		sv.value = truncateString(s, 40)
+	}
+	ss := Statements{}
+	ss.AddWithValue(sv.path, valueTokenFromInterface(sv.value))
+	return ss[0].withComment(plural(sv.size, unit))
+}
+
+// plural returns a count followed by a unit, adding an s to the
+// unit unless the count is one; e.g. 1 key, 2 keys
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// topLevelSizes returns the size of the compact JSON for each top level
+// key of a document, largest first, along with the size of the whole
+func topLevelSizes(root interface{}) ([]sizedValue, int, error) {
+	total, err := encodeJSON(root, UngronOptions{Compact: true})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var out []sizedValue
+	add := func(path Statement, v interface{}) error {
+		j, err := encodeJSON(v, UngronOptions{Compact: true})
+		if err != nil {
+			return err
+		}
+		out = append(out, sizedValue{path, v, len(j)})
+		return nil
+	}
+
+	prefix := Statement{{"json", TypBare}}
+	switch vv := root.(type) {
+	case json.OrderedObject:
+		for _, m := range vv {
+			if err := add(prefix.withKey(m.Key), m.Value); err != nil {
+				return nil, 0, err
+			}
+		}
+	case []interface{}:
+		for i, e := range vv {
+			if err := add(prefix.withNumericKey(i), e); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return largest(out, 0), len(total), nil
+}
+
+// Stats reads a document and writes statistics about it: the number of
+// statements, the depth of the deepest path, the number of values of each
+// type, the largest arrays and objects, the longest strings, and the size
+// of the JSON for each top level key. The top argument limits the number
+// of values listed; zero lists them all. Input with more than one
+// document is an error, rather than reporting on only the first
+func Stats(r io.Reader, w io.Writer, conv StatementConv, format InputFormat, top int) (int, error) {
+	d := MakeDecoder(r, format, false)
+	var v interface{}
+	err := d.Decode(&v)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to read document: %s", err)
+	}
+	var next interface{}
+	err = d.Decode(&next)
+	if err == nil {
+		return exitReadInput, errors.New("failed to read document: the input has more than one document")
+	}
+	if err != io.EOF {
+		return exitReadInput, fmt.Errorf("failed to read document: %s", err)
+	}
+
+	st := &docStats{types: make(map[TokenTyp]int)}
+	st.add(Statement{{"json", TypBare}}, 0, v)
+
+	fmt.Fprintf(w, "statements: %d\n", st.statements)
+	fmt.Fprintf(w, "max depth: %d\n", st.maxDepth)
+
+	fmt.Fprintln(w, "\nvalues:")
+	for _, t := range statsValueTypes {
+		fmt.Fprintf(w, "  %-8s %d\n", t.name, st.types[t.typ])
+	}
+
+	sections := []struct {
+		title  string
+		values []sizedValue
+		unit   string
+	}{
+		{"largest arrays", st.arrays, "element"},
+		{"largest objects", st.objects, "key"},
+		{"longest strings", st.strings, "character"},
+	}
+	for _, sec := range sections {
+		if len(sec.values) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", sec.title)
+		for _, sv := range largest(sec.values, top) {
+			fmt.Fprintln(w, conv(sizedStatement(sv, sec.unit)))
+		}
+	}
+
+	sizes, total, err := topLevelSizes(v)
+	if err != nil {
+		return exitJSONEncode, fmt.Errorf("failed to measure document: %s", err)
+	}
+	if len(sizes) > 0 {
+		fmt.Fprintf(w, "\nsize per top-level key (%d bytes in total):\n", total)
+		for _, sv := range sizes {
+			s := sizedStatement(sv, "byte")
+			s = s.withComment(fmt.Sprintf("%.1f%%", 100*float64(sv.size)/float64(total)))
+			fmt.Fprintln(w, conv(s))
+		}
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,78 @@
+package gron
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	in, err := os.Open("testdata/two.json")
+	if err != nil {
+		t.Fatalf("failed to open input file: %s", err)
+	}
+	defer in.Close()
+
+	want := `statements: 10
+max depth: 2
+
+values:
+  objects  2
+  arrays   1
+  strings  7
+  numbers  0
+  true     0
+  false    0
+  null     0
+
+largest arrays:
+json.likes = []; // 3 elements
+
+largest objects:
+json = {}; // 4 keys
+
+longest strings:
+json.github = "https://github.com/tomnomnom/"; // 29 characters
+
+size per top-level key (152 bytes in total):
+json.contact = {}; // 53 bytes, 34.9%
+json.github = "https://github.com/tomnomnom/"; // 31 bytes, 20.4%
+json.likes = []; // 24 bytes, 15.8%
+json.name = "Tom"; // 5 bytes, 3.3%
+`
+
+	out := &bytes.Buffer{}
+	code, err := Stats(in, out, StatementToString, InputJSON, 1)
+	if code != exitOK || err != nil {
+		t.Fatalf("want exitOK and nil error; have %d and %s", code, err)
+	}
+	if out.String() != want {
+		t.Errorf("want:\n%s\nhave:\n%s", want, out.String())
+	}
+}
+
+func TestStatsSingular(t *testing.T) {
+	out := &bytes.Buffer{}
+	code, err := Stats(strings.NewReader(`{"a":["x"]}`), out, StatementToString, InputJSON, 0)
+	if code != exitOK || err != nil {
+		t.Fatalf("want exitOK and nil error; have %d and %s", code, err)
+	}
+	for _, want := range []string{
+		`json.a = []; // 1 element`,
+		`json = {}; // 1 key`,
+		`json.a[0] = "x"; // 1 character`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("want output to contain %s; have:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestStatsMultipleDocuments(t *testing.T) {
+	code, err := Stats(strings.NewReader("1 2"), io.Discard, StatementToString, InputJSON, 0)
+	if code != exitReadInput || err == nil {
+		t.Errorf("want exitReadInput and an error for input with two documents; have %d and %v", code, err)
+	}
+}
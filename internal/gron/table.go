@@ -0,0 +1,277 @@
+package gron
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// tableCells calls add with the path and value of each scalar beneath
+// a value, and of each empty object or array, in document order
+func tableCells(prefix Statement, v interface{}, add func(Statement, interface{})) {
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		if len(vv) == 0 {
+			add(prefix, v)
+		}
+		for _, m := range vv {
+			tableCells(prefix.withKey(m.Key), m.Value, add)
+		}
+	case []interface{}:
+		if len(vv) == 0 {
+			add(prefix, v)
+		}
+		for i, e := range vv {
+			tableCells(prefix.withNumericKey(i), e, add)
+		}
+	default:
+		add(prefix, v)
+	}
+}
+
+// tableHeader returns the path of a statement relative to its leading
+// bare word, as written in the header row of a table
+// E.g:
+//
+//	json.address.city -> address.city
+//	json.tags[0]      -> tags[0]
+func tableHeader(path Statement) string {
+	var out strings.Builder
+	for _, t := range path[1:] {
+		out.WriteString(t.Text)
+	}
+	return strings.TrimPrefix(out.String(), ".")
+}
+
+// tableCell returns the text written in a table for a value. Strings are
+// written without quotes and null is written as an empty cell. Strings
+// that would be read back as some other value, such as "123" or "true",
+// are written quoted so that they're still strings when they're read
+// back with TableTypes, as is the empty string so that it isn't null
+func tableCell(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if vv == "" {
+			return quoteString(vv)
+		}
+		if s, ok := parseValue(vv, false).(string); ok && s == vv {
+			return vv
+		}
+		return quoteString(vv)
+	default:
+		return valueTokenFromInterface(v).Text
+	}
+}
+
+// WriteTable reads a document and writes the array at a gron path as a
+// table with one row per element. The header row holds the path of each
+// value beneath the elements, such as address.city or tags[0].
+// Comma separates the cells; e.g. ',' for CSV or '\t' for TSV. The
+// values are redacted, decoded or cut short as opts says
+func WriteTable(r io.Reader, w io.Writer, in InputFormat, path string, comma rune, opts GronOptions) (int, error) {
+	keys, err := parsePath(path)
+	if err != nil {
+		return exitFormStatements, err
+	}
+
+	var doc interface{}
+	err = makeDecoder(r, in, false, opts).Decode(&doc)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to read document: %s", err)
+	}
+	doc = opts.applyTo(Statement{{"json", TypBare}}, doc)
+
+	v, ok := valueAt(doc, keys)
+	if !ok {
+		return exitFormStatements, fmt.Errorf("nothing found at `%s`", path)
+	}
+	elems, ok := v.([]interface{})
+	if !ok {
+		return exitFormStatements, fmt.Errorf("the value at `%s` is not an array", path)
+	}
+
+	// Every path found in any element gets a column, in the order
+	// that they're first found. Scalars and empty objects and arrays
+	// have no path beneath them to name a column with
+	var headers []string
+	columns := make(map[string]int)
+	rows := make([]map[int]string, len(elems))
+	for i, e := range elems {
+		rows[i] = make(map[int]string)
+		unnamed := false
+		tableCells(Statement{{"json", TypBare}}, e, func(p Statement, cell interface{}) {
+			h := tableHeader(p)
+			if h == "" {
+				unnamed = true
+				return
+			}
+			col, ok := columns[h]
+			if !ok {
+				col = len(headers)
+				columns[h] = col
+				headers = append(headers, h)
+			}
+			rows[i][col] = tableCell(cell)
+		})
+		if unnamed {
+			return exitFormStatements, fmt.Errorf("element %d of `%s` is not an object or array with values, so it can't be written as a row", i, path)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	err = cw.Write(headers)
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write table")
+	}
+	record := make([]string, len(headers))
+	for _, row := range rows {
+		for col := range record {
+			record[col] = row[col]
+		}
+		err = cw.Write(record)
+		if err != nil {
+			return exitJSONEncode, errors.Wrap(err, "failed to write table")
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write table")
+	}
+	return exitOK, nil
+}
+
+// headerPath returns the gron path of the values in a column of a table
+// beneath each element. Headers that aren't gron paths are object keys,
+// including the empty header
+// E.g:
+//
+//	address.city -> json.address.city
+//	tags[0]      -> json.tags[0]
+//	first name   -> json["first name"]
+func headerPath(h string) string {
+	path := "json"
+	if h == "" {
+		return `json[""]`
+	}
+	if !strings.HasPrefix(h, "[") {
+		path += "."
+	}
+	path += h
+	if _, err := parsePath(path); err != nil {
+		return "json[" + quoteString(h) + "]"
+	}
+	return path
+}
+
+// tableValue returns the value of a cell in a table. Cells holding a JSON
+// string, which WriteTable writes for strings that could be mistaken for
+// other values, are unquoted. With types set, cells holding any other JSON
+// value are read as that value, and the rest are strings
+func tableValue(cell string, types bool) interface{} {
+	v := parseValue(cell, false)
+	if _, ok := v.(string); ok || types {
+		return v
+	}
+	return cell
+}
+
+// A tableDecoder reads a table with a header row, such as CSV, as an
+// array with an object for each row, keyed by the headers. Empty cells
+// are left out and the rest are strings, unquoted if they're JSON strings.
+// With paths and types set, each cell is instead set at the path given
+// by the header of its column and read as JSON if it can be, so that
+// the table written by WriteTable is read back into the array it came
+// from. Array indexes in headers must then be smaller than the number
+// of columns, as they are in a table written by WriteTable, which has
+// at least one column for each element of an array
+type tableDecoder struct {
+	r    *csv.Reader
+	done bool
+
+	// types reads cells holding JSON values as those values
+	types bool
+
+	// paths reads the headers as paths rather than object keys
+	paths bool
+}
+
+// newTableDecoder returns a tableDecoder for cells separated by comma
+func newTableDecoder(r io.Reader, comma rune, types bool, paths bool) *tableDecoder {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.LazyQuotes = comma == '\t'
+	return &tableDecoder{r: cr, types: types, paths: paths}
+}
+
+// Decode reads the whole table into v, which must be an *interface{}.
+// There's only one value in a table, so later calls return io.EOF
+func (d *tableDecoder) Decode(v interface{}) error {
+	out, ok := v.(*interface{})
+	if !ok {
+		return errors.New("a table can only be decoded into an interface{}")
+	}
+	if d.done {
+		return io.EOF
+	}
+	d.done = true
+
+	headers, err := d.r.Read()
+	if err == io.EOF {
+		return errors.New("the table has no header row")
+	}
+	if err != nil {
+		return err
+	}
+	paths := make([]string, len(headers))
+	for i, h := range headers {
+		if !d.paths {
+			paths[i] = "json[" + quoteString(h) + "]"
+			continue
+		}
+		paths[i] = headerPath(h)
+
+		keys, err := parsePath(paths[i])
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if index, ok := k.(int); ok && index >= len(headers) {
+				return fmt.Errorf("header `%s` has an array index of %d but the table only has %d columns", h, index, len(headers))
+			}
+		}
+	}
+
+	rows := make([]interface{}, 0)
+	for {
+		record, err := d.r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var row interface{} = json.OrderedObject{}
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			row, err = SetPath(row, paths[i], tableValue(cell, d.types))
+			if err != nil {
+				line, _ := d.r.FieldPos(i)
+				return errors.Wrapf(err, "failed to read line %d", line)
+			}
+		}
+		rows = append(rows, row)
+	}
+	*out = rows
+	return nil
+}
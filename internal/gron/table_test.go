@@ -0,0 +1,217 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	in := `{"users":[{"name":"Tom","address":{"city":"Leeds","zip":null},"tags":["a","b"]},{"name":"Ann, Jr","tags":[],"first name":"x","age":30}]}`
+
+	cases := []struct {
+		path  string
+		comma rune
+		want  string
+	}{
+		{"json.users", ',', "name,address.city,address.zip,tags[0],tags[1],tags,\"[\"\"first name\"\"]\",age\n" +
+			"Tom,Leeds,,a,b,,,\n" +
+			"\"Ann, Jr\",,,,,[],x,30\n"},
+		{"json.users", '\t', "name\taddress.city\taddress.zip\ttags[0]\ttags[1]\ttags\t\"[\"\"first name\"\"]\"\tage\n" +
+			"Tom\tLeeds\t\ta\tb\t\t\t\n" +
+			"Ann, Jr\t\t\t\t\t[]\tx\t30\n"},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := WriteTable(strings.NewReader(in), out, InputJSON, c.path, c.comma, GronOptions{})
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to write table at %s: %d, %s", c.path, code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%q\nhave:\n%q", c.want, out.String())
+		}
+	}
+}
+
+func TestWriteTableOptions(t *testing.T) {
+	in := `{"users":[{"name":"a","password":"hunter2","key":"k1"},{"name":"bcdef","password":"swordfish","key":"k2"},{"name":"c"}]}`
+
+	redact, err := NewRedactor(nil, []string{"json.users[1].key"})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	cases := []struct {
+		opts GronOptions
+		want string
+	}{
+		{GronOptions{Redact: redact}, "name,password,key\n" +
+			"a,[REDACTED],k1\n" +
+			"bcdef,[REDACTED],[REDACTED]\n" +
+			"c,,\n"},
+		{GronOptions{MaxArray: 2, MaxStringLength: 3}, "name,password,key\n" +
+			"a,hun…,k1\n" +
+			"bcd…,swo…,k2\n"},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := WriteTable(strings.NewReader(in), out, InputJSON, "json.users", ',', c.opts)
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to write table: %d, %s", code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%q\nhave:\n%q", c.want, out.String())
+		}
+	}
+}
+
+func TestWriteTableErrors(t *testing.T) {
+	in := `{"users":{"name":"Tom"},"plain":["a","b"],"empty":[{"a":1},{}]}`
+	for _, path := range []string{"json.users", "json.missing", "json..users", "json.plain", "json.empty"} {
+		code, err := WriteTable(strings.NewReader(in), &bytes.Buffer{}, InputJSON, path, ',', GronOptions{})
+		if code == exitOK || err == nil {
+			t.Errorf("want error writing table at %s; have none", path)
+		}
+	}
+}
+
+func TestHeaderPath(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"", `json[""]`},
+		{"name", "json.name"},
+		{"address.city", "json.address.city"},
+		{"tags[0]", "json.tags[0]"},
+		{`["first name"]`, `json["first name"]`},
+		{"first name", `json["first name"]`},
+		{"e-mail", `json["e-mail"]`},
+	}
+
+	for _, c := range cases {
+		have := headerPath(c.in)
+		if have != c.want {
+			t.Errorf("want %s for %q; have %s", c.want, c.in, have)
+		}
+	}
+}
+
+func TestGronTable(t *testing.T) {
+	in := "name,address.city,tags[0],tags[1],age,ok,first name\n" +
+		"Tom,Leeds,a,b,30,true,\n" +
+		"\"Ann, Jr\",,,,007,,x\n"
+
+	cases := []struct {
+		format InputFormat
+		in     string
+		opts   GronOptions
+		want   string
+	}{
+		{InputCSV, in, GronOptions{TableTypes: true, TablePaths: true}, `json = [];
+json[0] = {};
+json[0].name = "Tom";
+json[0].address = {};
+json[0].address.city = "Leeds";
+json[0].tags = [];
+json[0].tags[0] = "a";
+json[0].tags[1] = "b";
+json[0].age = 30;
+json[0].ok = true;
+json[1] = {};
+json[1].name = "Ann, Jr";
+json[1].age = "007";
+json[1]["first name"] = "x";
+`},
+		{InputCSV, in, GronOptions{}, `json = [];
+json[0] = {};
+json[0].name = "Tom";
+json[0]["address.city"] = "Leeds";
+json[0]["tags[0]"] = "a";
+json[0]["tags[1]"] = "b";
+json[0].age = "30";
+json[0].ok = "true";
+json[1] = {};
+json[1].name = "Ann, Jr";
+json[1].age = "007";
+json[1]["first name"] = "x";
+`},
+		{InputTSV, "email\tscore\na@example.com\t1.5\nb \"q\"\t\n", GronOptions{TableTypes: true}, `json = [];
+json[0] = {};
+json[0].email = "a@example.com";
+json[0].score = 1.5;
+json[1] = {};
+json[1].email = "b \"q\"";
+`},
+		{InputCSV, "id\n", GronOptions{}, "json = [];\n"},
+		{InputCSV, "note,zip,id\n\"\"\"\"\"\",\"\"\"true\"\"\",\"\"\"1\"\n", GronOptions{}, `json = [];
+json[0] = {};
+json[0].note = "";
+json[0].zip = "true";
+json[0].id = "\"1";
+`},
+		{InputCSV, ",b\nx,y\n", GronOptions{TablePaths: true}, `json = [];
+json[0] = {};
+json[0][""] = "x";
+json[0].b = "y";
+`},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := Gron(strings.NewReader(c.in), out, StatementToString, c.format, false, false, c.opts)
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to gron table: %d, %s", code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%s\nhave:\n%s", c.want, out.String())
+		}
+	}
+}
+
+func TestTableRoundTrip(t *testing.T) {
+	in := `[{"id":1,"user":{"name":"Tom","roles":["admin","dev"]},"tags":[],"meta":{}},{"id":2.5,"user":{"name":"x, \"y\""},"active":false},{"id":"123","user":{"name":"\"q\""},"active":"true"},{"id":"","user":{"name":""}}]`
+
+	table := &bytes.Buffer{}
+	code, err := WriteTable(strings.NewReader(in), table, InputJSON, "json", ',', GronOptions{})
+	if code != exitOK || err != nil {
+		t.Fatalf("failed to write table: %d, %s", code, err)
+	}
+
+	var v interface{}
+	err = newTableDecoder(table, ',', true, true).Decode(&v)
+	if err != nil {
+		t.Fatalf("failed to read table: %s", err)
+	}
+	out, err := encodeJSON(v, UngronOptions{Compact: true})
+	if err != nil {
+		t.Fatalf("failed to encode JSON: %s", err)
+	}
+	if string(out) != in {
+		t.Errorf("want %s; have %s", in, out)
+	}
+}
+
+func TestTableDecoderIndexLimit(t *testing.T) {
+	cases := []struct {
+		in      string
+		wantErr bool
+	}{
+		{"a[0],a[1]\nx,y\n", false},
+		{"a[1],b\nx,y\n", false},
+		{"a[2],b\nx,y\n", true},
+		{"a[5000]\nx\n", true},
+	}
+
+	for _, c := range cases {
+		var v interface{}
+		err := newTableDecoder(strings.NewReader(c.in), ',', false, true).Decode(&v)
+		if c.wantErr && err == nil {
+			t.Errorf("want error for headers of %q; have none", c.in)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("want no error for headers of %q; have %s", c.in, err)
+		}
+	}
+}
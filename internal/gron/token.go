@@ -3,6 +3,8 @@ package gron
 import (
 	"bytes"
 	"fmt"
+	"math"
+	"strconv"
 	"unicode"
 
 	json "github.com/virtuald/go-ordered-json"
@@ -49,6 +51,21 @@ const (
 
 	// Error token
 	TypError
+
+	// Keyword types
+	TypDelete // delete
+
+	// Undefined is not a value; assigning it removes a key
+	TypUndefined // undefined
+
+	// A comment at the end of a statement; like '// !!timestamp'
+	TypComment
+
+	// Any array index; like '*' in json.users[*] = object (1/1);
+	TypWildcard
+
+	// A summary of the types found at a path; like 'string (98/100)'
+	TypTypes
 )
 
 // isValue returns true if the token is a valid value type
@@ -73,8 +90,11 @@ func (t Token) isPunct() bool {
 
 // format returns the formatted version of the token text
 func (t Token) format() string {
-	if t.Typ == TypEquals {
+	switch t.Typ {
+	case TypEquals:
 		return " " + t.Text + " "
+	case TypComment:
+		return " " + t.Text
 	}
 	return t.Text
 }
@@ -82,12 +102,15 @@ func (t Token) format() string {
 // formatColor returns the colored formatted version of the token text
 func (t Token) formatColor() string {
 	text := t.Text
-	if t.Typ == TypEquals {
-		text = " " + text + " "
-	}
 	fn, ok := sprintFns[t.Typ]
 	if ok {
-		return fn(text)
+		text = fn(text)
+	}
+	switch t.Typ {
+	case TypEquals:
+		return " " + text + " "
+	case TypComment:
+		return " " + text
 	}
 	return text
 }
@@ -101,8 +124,12 @@ func valueTokenFromInterface(v interface{}) Token {
 		return Token{"{}", TypEmptyObject}
 	case []interface{}:
 		return Token{"[]", TypEmptyArray}
-	case int, float64:
-		return Token{fmt.Sprintf("%v", vv), TypNumber}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return Token{fmt.Sprintf("%d", vv), TypNumber}
+	case float32:
+		return floatToken(float64(vv), 32)
+	case float64:
+		return floatToken(vv, 64)
 	case json.Number:
 		return Token{vv.String(), TypNumber}
 	case string:
@@ -119,6 +146,33 @@ func valueTokenFromInterface(v interface{}) Token {
 	}
 }
 
+// floatToken returns the token for a float, formatted the same way as
+// encoding/json so that it's never written with a needless exponent
+// E.g:
+//
+//	1000000 not 1e+06
+//	1e-7 not 1e-07
+func floatToken(f float64, bits int) Token {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return Token{"", TypError}
+	}
+
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	out := strconv.FormatFloat(f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(out)
+		if n >= 4 && out[n-4] == 'e' && out[n-3] == '-' && out[n-2] == '0' {
+			out = out[:n-2] + out[n-1:]
+		}
+	}
+	return Token{out, TypNumber}
+}
+
 // quoteString takes a string and returns a quoted and
 // escaped string valid for use in gron output
 func quoteString(s string) string {
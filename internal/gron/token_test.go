@@ -49,3 +49,27 @@ func BenchmarkValueTokenFromInterface(b *testing.B) {
 		}
 	}
 }
+
+func TestValueTokenFromNumbers(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want string
+	}{
+		{1000000, "1000000"},
+		{int64(9223372036854775807), "9223372036854775807"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{1000000.0, "1000000"},
+		{0.1, "0.1"},
+		{1e21, "1e+21"},
+		{1e-7, "1e-7"},
+		{float32(0.1), "0.1"},
+		{-0.000001, "-0.000001"},
+	}
+
+	for _, c := range cases {
+		have := valueTokenFromInterface(c.in)
+		if have != (Token{c.want, TypNumber}) {
+			t.Errorf("want %s for %#v; have %#v", c.want, c.in, have)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package gron
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// An OutputFormat is something other than statements that a
+// document can be turned into
+type OutputFormat int
+
+const (
+	// OutputGoStruct is Go type definitions with json tags
+	OutputGoStruct OutputFormat = iota
+
+	// OutputTypeScript is TypeScript interfaces
+	OutputTypeScript
+
+	// OutputCSV is a table of the elements of an array, written
+	// as comma separated values
+	OutputCSV
+
+	// OutputTSV is a table of the elements of an array, written
+	// as tab separated values
+	OutputTSV
+
+	// OutputYAML is a YAML document, written when ungronning
+	OutputYAML
+
+	// OutputXML is an XML document, written when ungronning
+	OutputXML
+)
+
+// OutputFormatFromString returns the OutputFormat for a name as
+// accepted on the command line
+func OutputFormatFromString(name string) (OutputFormat, error) {
+	switch strings.ToLower(name) {
+	case "go", "go-struct":
+		return OutputGoStruct, nil
+	case "ts", "typescript":
+		return OutputTypeScript, nil
+	case "csv":
+		return OutputCSV, nil
+	case "tsv":
+		return OutputTSV, nil
+	case "yaml", "yml":
+		return OutputYAML, nil
+	case "xml":
+		return OutputXML, nil
+	default:
+		return OutputGoStruct, fmt.Errorf("unknown output format `%s`", name)
+	}
+}
+
+// commonInitialisms are the words that Go style writes in capitals
+// in identifiers; e.g. UserID rather than UserId
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "QPS": true, "RAM": true,
+	"RPC": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "UID": true, "URI": true,
+	"URL": true, "UTF8": true, "UUID": true, "XML": true,
+}
+
+// exportedName returns an exported Go identifier for an object key
+// E.g:
+//
+//	user_id    -> UserID
+//	createdAt  -> CreatedAt
+//	2fa-secret -> X2faSecret
+//	名前       -> X名前
+func exportedName(k string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(k)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		// A new word starts at an upper case letter following a lower
+		// case one, or ending a run of upper case; e.g. HTTPServer
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	var out strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); commonInitialisms[upper] {
+			out.WriteString(upper)
+			continue
+		}
+		rs := []rune(w)
+		out.WriteRune(unicode.ToUpper(rs[0]))
+		out.WriteString(string(rs[1:]))
+	}
+
+	name := out.String()
+	if name == "" {
+		return "Field"
+	}
+	// Only names starting with an upper case letter are exported, and
+	// letters such as 名 have no upper case
+	if r := []rune(name)[0]; !unicode.IsUpper(r) {
+		return "X" + name
+	}
+	return name
+}
+
+// singular returns the name for the elements of an array with a name,
+// dropping a plural ending where there is one
+// E.g:
+//
+//	Users     -> User
+//	Companies -> Company
+//	Data      -> DataElement
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name + "Element"
+	}
+}
+
+// uniqueName returns name, or name followed by a number if name is
+// already used, and marks it as used
+func uniqueName(name string, used map[string]bool) string {
+	out := name
+	for i := 2; used[out]; i++ {
+		out = fmt.Sprintf("%s%d", name, i)
+	}
+	used[out] = true
+	return out
+}
+
+// A typeWriter writes type definitions for the values found at a
+// schemaNode, one named type for each kind of object found
+type typeWriter struct {
+	format OutputFormat
+	used   map[string]bool
+	defs   []*bytes.Buffer
+}
+
+// nonNullTypes returns the names of the types found at a node other
+// than null, the most common first
+func nonNullTypes(n *schemaNode) []string {
+	var out []string
+	for _, name := range n.typeNames() {
+		if name != "null" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// optional reports whether an object key is missing from some of
+// the objects it was found in
+func optional(n *schemaNode) bool {
+	return n.objectKey && n.found() < n.possible()
+}
+
+// typeOf returns the type of the values found at a node, adding
+// definitions for any objects found there. The name is used for
+// the definitions, so that the type for a user key is User
+func (tw *typeWriter) typeOf(n *schemaNode, name string) string {
+	types := nonNullTypes(n)
+	nullable := n.types["null"] > 0
+
+	if tw.format == OutputTypeScript {
+		if len(types) == 0 {
+			return "null"
+		}
+		parts := make([]string, len(types))
+		for i, typ := range types {
+			parts[i] = tw.singleType(n, typ, name)
+		}
+		if nullable {
+			parts = append(parts, "null")
+		}
+		return strings.Join(parts, " | ")
+	}
+
+	if len(types) != 1 {
+		return "interface{}"
+	}
+	t := tw.singleType(n, types[0], name)
+
+	// Go can only say that a value might be missing or null
+	// with a pointer
+	if (nullable || optional(n)) && !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "map[") {
+		return "*" + t
+	}
+	return t
+}
+
+// singleType returns the type of the values of one JSON type found
+// at a node
+func (tw *typeWriter) singleType(n *schemaNode, typ string, name string) string {
+	ts := tw.format == OutputTypeScript
+	switch typ {
+	case "string":
+		return "string"
+	case "boolean":
+		if ts {
+			return "boolean"
+		}
+		return "bool"
+	case "number":
+		if ts {
+			return "number"
+		}
+		if n.integers == n.types["number"] {
+			return "int64"
+		}
+		return "float64"
+	case "array":
+		elem := "unknown"
+		if !ts {
+			elem = "interface{}"
+		}
+		if n.elems != nil {
+			elem = tw.typeOf(n.elems, singular(name))
+		}
+		if ts && strings.Contains(elem, " ") {
+			return "(" + elem + ")[]"
+		}
+		if ts {
+			return elem + "[]"
+		}
+		return "[]" + elem
+	default:
+		if len(n.members) == 0 {
+			if ts {
+				return "Record<string, unknown>"
+			}
+			return "map[string]interface{}"
+		}
+		return tw.define(n, name)
+	}
+}
+
+// define adds the definition of a named type for the objects found
+// at a node and returns its name
+func (tw *typeWriter) define(n *schemaNode, name string) string {
+	name = uniqueName(name, tw.used)
+	def := &bytes.Buffer{}
+	tw.defs = append(tw.defs, def)
+
+	if tw.format == OutputTypeScript {
+		fmt.Fprintf(def, "export interface %s {\n", name)
+		for _, m := range n.members {
+			key := m.key
+			if !validIdentifier(key) {
+				key = quoteString(key)
+			}
+			if optional(m) {
+				key += "?"
+			}
+			fmt.Fprintf(def, "  %s: %s;\n", key, tw.typeOf(m, exportedName(m.key)))
+		}
+		fmt.Fprintln(def, "}")
+		return name
+	}
+
+	fmt.Fprintf(def, "type %s struct {\n", name)
+	fields := make(map[string]bool)
+	for _, m := range n.members {
+		if !validTagName(m.key) {
+			fmt.Fprintf(def, "\t// %s can't be named in a json tag so it isn't decoded\n", quoteString(m.key))
+			continue
+		}
+		field := uniqueName(exportedName(m.key), fields)
+		tag := m.key
+		if optional(m) {
+			tag += ",omitempty"
+		} else if tag == "-" {
+			// A tag of just - skips the field; a trailing comma names it -
+			tag += ","
+		}
+		tag = "`json:" + strconv.Quote(tag) + "`"
+		fmt.Fprintf(def, "\t%s %s %s\n", field, tw.typeOf(m, field), tag)
+	}
+	fmt.Fprintln(def, "}")
+	return name
+}
+
+// validTagName returns true if encoding/json accepts a key as the name
+// in a json tag. Keys with commas, quotes, backslashes or backticks can't
+// be named, and nor can the empty key
+func validTagName(k string) bool {
+	if k == "" {
+		return false
+	}
+	for _, r := range k {
+		if strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r) {
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// write writes the definitions for a root node with a name
+func (tw *typeWriter) write(w io.Writer, root *schemaNode, name string) error {
+	if nonNull := nonNullTypes(root); len(nonNull) == 1 && nonNull[0] == "object" && len(root.members) > 0 && root.types["null"] == 0 {
+		tw.define(root, name)
+	} else {
+		// The root gets the name asked for even when it isn't an object
+		def := &bytes.Buffer{}
+		tw.defs = append(tw.defs, def)
+		tw.used[name] = true
+		t := tw.typeOf(root, name)
+		if tw.format == OutputTypeScript {
+			fmt.Fprintf(def, "export type %s = %s;\n", name, t)
+		} else {
+			fmt.Fprintf(def, "type %s %s\n", name, t)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	for i, def := range tw.defs {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		out.Write(def.Bytes())
+	}
+
+	src := out.Bytes()
+	if tw.format == OutputGoStruct {
+		var err error
+		src, err = format.Source(src)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(src)
+	return err
+}
+
+// WriteTypes reads a document and writes type definitions
+// that it can be decoded into: Go structs with json tags, or TypeScript
+// interfaces. The elements of arrays are merged so that one type covers
+// them all, keys missing from some objects are optional, and values that
+// are sometimes null are nullable. In stream mode every document in the
+// input is a sample of the same type. The samples are redacted, decoded
+// or cut short as opts says
+// E.g:
+//
+//	type Root struct {
+//		Name  string   `json:"name"`
+//		Likes []string `json:"likes"`
+//	}
+func WriteTypes(r io.Reader, w io.Writer, in InputFormat, stream bool, format OutputFormat, name string, opts GronOptions) (int, error) {
+	root := newSchemaNode(Statement{{"json", TypBare}}, nil, false)
+	d := makeDecoder(r, in, false, opts)
+
+	if stream {
+		docs, err := root.addDocuments(d, opts)
+		if err != nil {
+			return exitFormStatements, err
+		}
+		if docs == 0 {
+			return exitReadInput, errors.New("no documents were read")
+		}
+	} else {
+		var v interface{}
+		err := d.Decode(&v)
+		if err != nil {
+			return exitFormStatements, fmt.Errorf("failed to form statements: %s", err)
+		}
+		root.add(opts.applyTo(root.path, v))
+	}
+
+	tw := &typeWriter{format: format, used: make(map[string]bool)}
+	err := tw.write(w, root, exportedName(name))
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to write types")
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,124 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExportedName(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"name", "Name"},
+		{"user_id", "UserID"},
+		{"createdAt", "CreatedAt"},
+		{"HTTPServer", "HTTPServer"},
+		{"avatar-url", "AvatarURL"},
+		{"2fa", "X2fa"},
+		{"名前", "X名前"},
+		{"user_名前", "User名前"},
+		{"", "Field"},
+		{"@", "Field"},
+	}
+
+	for _, c := range cases {
+		have := exportedName(c.in)
+		if have != c.want {
+			t.Errorf("want %q for %q; have %q", c.want, c.in, have)
+		}
+	}
+}
+
+func TestWriteTypes(t *testing.T) {
+	in := `{"users":[{"id":1,"email":"a@x","score":1.5,"address":{"city":"x"}},{"id":2,"email":null,"score":2,"tags":["a"]}],"meta":{}}`
+
+	cases := []struct {
+		format OutputFormat
+		stream bool
+		in     string
+		want   string
+	}{
+		{OutputGoStruct, false, in, "type Root struct {\n" +
+			"\tUsers []User                 `json:\"users\"`\n" +
+			"\tMeta  map[string]interface{} `json:\"meta\"`\n" +
+			"}\n" +
+			"\n" +
+			"type User struct {\n" +
+			"\tID      int64    `json:\"id\"`\n" +
+			"\tEmail   *string  `json:\"email\"`\n" +
+			"\tScore   float64  `json:\"score\"`\n" +
+			"\tAddress *Address `json:\"address,omitempty\"`\n" +
+			"\tTags    []string `json:\"tags,omitempty\"`\n" +
+			"}\n" +
+			"\n" +
+			"type Address struct {\n" +
+			"\tCity string `json:\"city\"`\n" +
+			"}\n",
+		},
+		{OutputTypeScript, false, in, `export interface Root {
+  users: User[];
+  meta: Record<string, unknown>;
+}
+
+export interface User {
+  id: number;
+  email: string | null;
+  score: number;
+  address?: Address;
+  tags?: string[];
+}
+
+export interface Address {
+  city: string;
+}
+`,
+		},
+		{OutputGoStruct, true, "{\"a\":1}\n{\"a\":\"x\",\"b\":[]}\n", "type Root struct {\n" +
+			"\tA interface{}   `json:\"a\"`\n" +
+			"\tB []interface{} `json:\"b,omitempty\"`\n" +
+			"}\n",
+		},
+		{OutputTypeScript, true, "{\"a\":1}\n{\"a\":\"x\",\"b\":[]}\n", `export interface Root {
+  a: number | string;
+  b?: unknown[];
+}
+`,
+		},
+		{OutputTypeScript, false, `[1, null]`, "export type Root = (number | null)[];\n"},
+		{OutputGoStruct, false, `[1, null]`, "type Root []*int64\n"},
+		{OutputGoStruct, false, `{"a,b":1,"q\"":2,"-":3,"ok":true}`, "type Root struct {\n" +
+			"\t// \"a,b\" can't be named in a json tag so it isn't decoded\n" +
+			"\t// \"q\\\"\" can't be named in a json tag so it isn't decoded\n" +
+			"\tField int64 `json:\"-,\"`\n" +
+			"\tOk    bool  `json:\"ok\"`\n" +
+			"}\n",
+		},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := WriteTypes(strings.NewReader(c.in), out, InputJSON, c.stream, c.format, "root", GronOptions{})
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to write types for %s: %d, %s", c.in, code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%s\nhave:\n%s", c.want, out.String())
+		}
+	}
+}
+
+func TestWriteTypesJSON5(t *testing.T) {
+	in := "{\n  // comment\n  strict: true,\n}\n"
+	want := "type Root struct {\n\tStrict bool `json:\"strict\"`\n}\n"
+
+	out := &bytes.Buffer{}
+	code, err := WriteTypes(strings.NewReader(in), out, InputJSON5, false, OutputGoStruct, "root", GronOptions{})
+	if code != exitOK || err != nil {
+		t.Fatalf("failed to write types for JSON5: %d, %s", code, err)
+	}
+	if out.String() != want {
+		t.Errorf("want:\n%s\nhave:\n%s", want, out.String())
+	}
+}
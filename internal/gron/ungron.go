@@ -1,8 +1,9 @@
 // Ungronning is the reverse of gronning: turn statements
 // back into JSON. The expected input grammar is:
 //
-//   Input ::= '--'* Statement (Statement | '--')*
+//   Input ::= '--'* (Statement | Deletion) (Statement | Deletion | '--')*
 //   Statement ::= Path Space* "=" Space* Value ";" "\n"
+//   Deletion ::= ("delete" Space+ Path Space* | Path Space* "=" Space* "undefined") ";" "\n"
 //   Path ::= (BareWord) ("." BareWord | ("[" Key "]"))*
 //   Value ::= String | Number | "true" | "false" | "null" | "[]" | "{}"
 //   BareWord ::= (UnicodeLu | UnicodeLl | UnicodeLm | UnicodeLo | UnicodeNl | '$' | '_') (UnicodeLu | UnicodeLl | UnicodeLm | UnicodeLo | UnicodeNl | UnicodeMn | UnicodeMc | UnicodeNd | UnicodePc | '$' | '_')*
@@ -29,14 +30,9 @@ import (
 )
 
 // Ungron is the reverse of gron. Given assignment statements as input,
-// it returns JSON. The only option is optMonochrome
-func Ungron(r io.Reader, w io.Writer, outJson bool, colorize bool) (int, error) {
-	scanner := bufio.NewScanner(r)
+// it returns JSON. The opts control how the JSON is formatted
+func Ungron(r io.Reader, w io.Writer, outJson bool, colorize bool, opts UngronOptions) (int, error) {
 	var maker StatementMaker
-
-	// Allow larger internal buffer of the scanner (min: 64KiB ~ max: 1MiB)
-	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
-
 	if outJson {
 		maker = StatementFromJSONSpec
 	} else {
@@ -44,58 +40,131 @@ func Ungron(r io.Reader, w io.Writer, outJson bool, colorize bool) (int, error)
 	}
 
 	// Make a list of statements from the input
+	ss, code, err := readStatements(r, maker)
+	if err != nil {
+		return code, err
+	}
+	if opts.Lines {
+		// Elements of a stream that were filtered out, e.g. by grep,
+		// shouldn't be written as lines of null
+		ss = ss.withRootIndexesCompacted()
+	}
+	ss = ss.WithSparseArrays(opts.Sparse)
+
+	// turn the statements into a single merged interface{} type
+	merged, conflicts, err := ss.ToInterfaceWithConflicts(opts.Conflicts, opts.MaxIndex)
+	if opts.Warnings != nil {
+		// A conflict that caused an error is reported by the error itself
+		var se *StatementError
+		for _, c := range conflicts {
+			if err == nil || !errors.As(err, &se) || c.Last < se.Line {
+				fmt.Fprintf(opts.Warnings, "warning: %s\n", c.String())
+			}
+		}
+	}
+	if err != nil {
+		return exitParseStatements, err
+	}
+	merged, err = ss.encodeEmbedded(unwrapRoot(merged))
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to encode embedded JSON")
+	}
+
+	if opts.YAML {
+		err = encodeYAMLWithTags(w, merged, ss.yamlTags())
+		if err != nil {
+			return exitJSONEncode, errors.Wrap(err, "failed to convert statements to YAML")
+		}
+		return exitOK, nil
+	}
+
+	if opts.XML {
+		err = encodeXML(w, merged, opts)
+		if err != nil {
+			return exitJSONEncode, errors.Wrap(err, "failed to convert statements to XML")
+		}
+		return exitOK, nil
+	}
+
+	// In JSON Lines mode each element of a top level array
+	// is written as a separate compact JSON document
+	if opts.Lines {
+		lineOpts := opts
+		lineOpts.Compact = true
+
+		elems, ok := merged.([]interface{})
+		if !ok {
+			elems = []interface{}{merged}
+		}
+		for _, e := range elems {
+			err = writeJSON(w, e, colorize, lineOpts)
+			if err != nil {
+				return exitJSONEncode, errors.Wrap(err, "failed to convert statements to JSON")
+			}
+		}
+		return exitOK, nil
+	}
+
+	// Marshal the output into JSON to display to the user
+	err = writeJSON(w, merged, colorize, opts)
+	if err != nil {
+		return exitJSONEncode, errors.Wrap(err, "failed to convert statements to JSON")
+	}
+
+	return exitOK, nil
+}
+
+// readStatements reads one statement per line of input, so that the
+// position of each statement in the list matches its line number
+func readStatements(r io.Reader, maker StatementMaker) (Statements, int, error) {
+	scanner := bufio.NewScanner(r)
+
+	// Allow larger internal buffer of the scanner (min: 64KiB ~ max: 1MiB)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
 	var ss Statements
 	for scanner.Scan() {
 		s, err := maker(scanner.Text())
 		if err != nil {
-			return exitParseStatements, err
+			return nil, exitParseStatements, &StatementError{Line: len(ss) + 1, Err: err}
 		}
 		ss.Add(s)
 	}
 	if err := scanner.Err(); err != nil {
-		return exitReadInput, fmt.Errorf("failed to read input statements")
-	}
-
-	// turn the statements into a single merged interface{} type
-	merged, err := ss.ToInterface()
-	if err != nil {
-		return exitParseStatements, err
+		return nil, exitReadInput, fmt.Errorf("failed to read input statements")
 	}
+	return ss, exitOK, nil
+}
 
-	// If there's only one top level key and it's "json", make that the top level thing
-	switch merged.(type) {
+// unwrapRoot returns the value of the top level key if it's
+// the only one and it's "json", or the value itself otherwise
+func unwrapRoot(merged interface{}) interface{} {
+	switch m := merged.(type) {
 
 	case json.OrderedObject:
-		if mergedOrderObject, ok := merged.(json.OrderedObject); ok {
-			if len(mergedOrderObject) == 1 && mergedOrderObject[0].Key == "json" {
-				merged = mergedOrderObject[0].Value
-			}
+		if len(m) == 1 && m[0].Key == "json" {
+			return m[0].Value
 		}
 
 	case map[string]interface{}:
-		if mergedMap, ok := merged.(map[string]interface{}); ok {
-			if len(mergedMap) == 1 {
-				if _, exists := mergedMap["json"]; exists {
-					merged = mergedMap["json"]
-				}
-			}
+		if v, exists := m["json"]; exists && len(m) == 1 {
+			return v
 		}
 	}
+	return merged
+}
 
-	// Marshal the output into JSON to display to the user
-	out := &bytes.Buffer{}
-	enc := json.NewEncoder(out)
-	enc.SetIndent("", "  ")
-	enc.SetEscapeHTML(false)
-	err = enc.Encode(merged)
+// writeJSON encodes a value as JSON according to the options and
+// writes it to w followed by a single newline character
+func writeJSON(w io.Writer, v interface{}, colorize bool, opts UngronOptions) error {
+	j, err := encodeJSON(v, opts)
 	if err != nil {
-		return exitJSONEncode, errors.Wrap(err, "failed to convert statements to JSON")
+		return err
 	}
-	j := out.Bytes()
 
 	// If the output isn't monochrome, add color to the JSON
 	if colorize {
-		c, err := colorizeJSON(j)
+		c, err := colorizeJSON(j, opts.indent())
 
 		// If we failed to colorize the JSON for whatever reason,
 		// we'll just fall back to monochrome output, otherwise
@@ -103,18 +172,39 @@ func Ungron(r io.Reader, w io.Writer, outJson bool, colorize bool) (int, error)
 		if err == nil {
 			j = c
 		}
+
+		// The colorizer re-encodes strings, undoing any escaping;
+		// the color codes are ASCII so it's safe to escape again
+		if opts.ASCII {
+			j = escapeNonASCII(j)
+		}
 	}
 
-	// For whatever reason, the monochrome version of the JSON
-	// has a trailing newline character, but the colorized version
-	// does not. Strip the whitespace so that neither has the newline
+	// The colorized version of the JSON may carry surrounding
+	// whitespace. Strip it so that neither version has a newline
 	// character on the end, and then we'll add a newline in the
 	// Fprintf below
 	j = bytes.TrimSpace(j)
 
-	fmt.Fprintf(w, "%s\n", j)
+	_, err = fmt.Fprintf(w, "%s\n", j)
+	return err
+}
 
-	return exitOK, nil
+// A StatementError is an error with one of the statements being
+// ungronned. Line is the position of the statement in the input,
+// starting at 1
+type StatementError struct {
+	Line int
+	Err  error
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StatementError) Unwrap() error {
+	return e.Err
 }
 
 // errRecoverable is an error type to represent errors that
@@ -281,6 +371,11 @@ type lexFn func(*lexer) lexFn
 // lexStatement is the highest level lexFn. Its only job
 // is to determine which more specific lexFn to use
 func lexStatement(l *lexer) lexFn {
+	// A deletion statement starts with the delete keyword
+	if len(l.tokens) == 0 && isDeleteKeyword(l.text[l.pos:]) {
+		return lexDelete
+	}
+
 	r := l.peek()
 
 	switch {
@@ -290,6 +385,11 @@ func lexStatement(l *lexer) lexFn {
 		return lexBraces
 	case r == ' ', r == '=':
 		return lexValue
+	case r == ';':
+		// The end of a deletion statement
+		l.accept(";")
+		l.emit(TypSemi)
+		return lexComment
 	case r == '-':
 		// grep -A etc can add '--' lines to output
 		// we'll save the text but not actually do
@@ -303,6 +403,21 @@ func lexStatement(l *lexer) lexFn {
 	}
 }
 
+// isDeleteKeyword returns true if the text starts with the delete
+// keyword followed by a space or tab
+func isDeleteKeyword(text string) bool {
+	return strings.HasPrefix(text, "delete ") || strings.HasPrefix(text, "delete\t")
+}
+
+// lexDelete lexes the delete keyword at the start of a deletion statement
+func lexDelete(l *lexer) lexFn {
+	l.acceptRun("delt")
+	l.emit(TypDelete)
+	l.acceptRun(" \t")
+	l.ignore()
+	return lexStatement
+}
+
 // lexBareWord lexes for bare identifiers.
 // E.g: the 'foo' in 'foo.bar' or 'foo[0]' is a bare identifier
 func lexBareWord(l *lexer) lexFn {
@@ -383,6 +498,11 @@ func lexValue(l *lexer) lexFn {
 	if l.accept("=") {
 		l.emit(TypEquals)
 	} else {
+		// Deletion statements may have space before the semicolon
+		if l.accept(";") {
+			l.emit(TypSemi)
+			return lexComment
+		}
 		return nil
 	}
 	l.acceptRun(" ")
@@ -407,6 +527,14 @@ func lexValue(l *lexer) lexFn {
 		l.acceptRun("ul")
 		l.emit(TypNull)
 
+	case l.accept("u"):
+		l.acceptRun("ndefi")
+		if l.text[l.tokenStart:l.pos] != "undefined" {
+			l.emit(TypError)
+			return nil
+		}
+		l.emit(TypUndefined)
+
 	case l.accept("["):
 		l.accept("]")
 		l.emit(TypEmptyArray)
@@ -426,6 +554,7 @@ func lexValue(l *lexer) lexFn {
 
 	if l.accept(";") {
 		l.emit(TypSemi)
+		return lexComment
 	}
 
 	// The value should always be the last thing
@@ -433,6 +562,22 @@ func lexValue(l *lexer) lexFn {
 	return nil
 }
 
+// lexComment lexes an optional comment after the end of a statement
+// E.g: the '// !!timestamp' in 'json.t = "2001-12-14"; // !!timestamp'
+func lexComment(l *lexer) lexFn {
+	l.acceptRun(" ")
+	l.ignore()
+
+	if !strings.HasPrefix(l.text[l.pos:], "//") {
+		return nil
+	}
+	l.acceptRunFunc(func(r rune) bool {
+		return r != utf8.RuneError
+	})
+	l.emit(TypComment)
+	return nil
+}
+
 // lexIgnore accepts runes until the end of the input
 // and emits them as a typIgnored token
 func lexIgnore(l *lexer) lexFn {
@@ -443,8 +588,19 @@ func lexIgnore(l *lexer) lexFn {
 	return nil
 }
 
-// ungronTokens turns a slice of tokens into an actual datastructure
-func ungronTokens(ts []Token) (interface{}, error) {
+// ungronTokens turns a slice of tokens into an actual datastructure.
+// Array indexes larger than maxIndex are an error, as for checkIndex
+func ungronTokens(ts []Token, maxIndex int) (interface{}, error) {
+	return ungronInto(nil, ts, maxIndex)
+}
+
+// ungronInto merges the datastructure for a slice of tokens into an
+// existing one the same way as recursiveMerge, but updates the objects
+// and arrays along the path in place, so that assigning each element of
+// a long array in turn doesn't copy the whole array every time. Indexes
+// that would leave more than maxIndex missing elements in an array are
+// an error, as for checkIndex
+func ungronInto(into interface{}, ts []Token, maxIndex int) (interface{}, error) {
 	if len(ts) == 0 {
 		return nil, errRecoverable{"empty input"}
 	}
@@ -453,6 +609,8 @@ func ungronTokens(ts []Token) (interface{}, error) {
 		return nil, errRecoverable{"ignored token"}
 	}
 
+	ts = Statement(ts).withoutComment()
+
 	if ts[len(ts)-1].Typ == TypError {
 		return nil, errors.New("invalid statement")
 	}
@@ -468,11 +626,7 @@ func ungronTokens(ts []Token) (interface{}, error) {
 	switch {
 	case t.isPunct():
 		// Skip the token
-		val, err := ungronTokens(ts[1:])
-		if err != nil {
-			return nil, err
-		}
-		return val, nil
+		return ungronInto(into, ts[1:], maxIndex)
 
 	case t.isValue():
 		var val interface{}
@@ -483,23 +637,47 @@ func ungronTokens(ts []Token) (interface{}, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid value `%s`", t.Text)
 		}
-		return val, nil
+		if into == nil {
+			return val, nil
+		}
+		return recursiveMerge(into, val)
 
-	case t.Typ == TypBare:
-		val, err := ungronTokens(ts[1:])
-		if err != nil {
-			return nil, err
+	case t.Typ == TypBare || t.Typ == TypQuotedKey:
+		key := t.Text
+		if t.Typ == TypQuotedKey {
+			err := json.Unmarshal([]byte(t.Text), &key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted key `%s`", t.Text)
+			}
 		}
-		out := json.OrderedObject{{Key: t.Text, Value: val}}
-		return out, nil
 
-	case t.Typ == TypQuotedKey:
-		val, err := ungronTokens(ts[1:])
+		obj, ok := into.(json.OrderedObject)
+		if !ok {
+			val, err := ungronInto(nil, ts[1:], maxIndex)
+			if err != nil {
+				return nil, err
+			}
+			out := json.OrderedObject{{Key: key, Value: val}}
+			if into == nil {
+				return out, nil
+			}
+			return recursiveMerge(into, out)
+		}
+		for i, m := range obj {
+			if m.Key == key {
+				val, err := ungronInto(m.Value, ts[1:], maxIndex)
+				if err != nil {
+					return nil, err
+				}
+				obj[i].Value = val
+				return obj, nil
+			}
+		}
+		val, err := ungronInto(nil, ts[1:], maxIndex)
 		if err != nil {
 			return nil, err
 		}
-		out := json.OrderedObject{{Key: strings.Trim(t.Text, `"`), Value: val}}
-		return out, nil
+		return append(obj, json.Member{Key: key, Value: val}), nil
 
 	case t.Typ == TypNumericKey:
 		key, err := strconv.Atoi(t.Text)
@@ -507,21 +685,77 @@ func ungronTokens(ts []Token) (interface{}, error) {
 			return nil, fmt.Errorf("invalid integer key `%s`", t.Text)
 		}
 
-		val, err := ungronTokens(ts[1:])
+		arr, ok := into.([]interface{})
+		err = checkIndex(key, len(arr), maxIndex)
 		if err != nil {
 			return nil, err
 		}
+		if !ok {
+			val, err := ungronInto(nil, ts[1:], maxIndex)
+			if err != nil {
+				return nil, err
+			}
+
+			// There needs to be at least key + 1 space in the array
+			out := make([]interface{}, key+1)
+			out[key] = val
+			if into == nil {
+				return out, nil
+			}
+			return recursiveMerge(into, out)
+		}
 
-		// There needs to be at least key + 1 space in the array
-		out := make([]interface{}, key+1)
-		out[key] = val
-		return out, nil
+		for len(arr) <= key {
+			arr = append(arr, nil)
+		}
+		val, err := ungronInto(arr[key], ts[1:], maxIndex)
+		if err != nil {
+			return nil, err
+		}
+		// As for recursiveSliceMerge, null doesn't replace an element
+		if val != nil {
+			arr[key] = val
+		}
+		return arr, nil
 
 	default:
 		return nil, fmt.Errorf("unexpected token `%s`", t.Text)
 	}
 }
 
+// deletePath removes the value at the path keys from v and returns
+// the updated value. Removing an array element shifts the elements
+// after it down by one. Paths that don't exist are ignored
+func deletePath(v interface{}, keys []interface{}) interface{} {
+	if len(keys) == 0 {
+		return v
+	}
+
+	switch k := keys[0].(type) {
+	case string:
+		child, ok := objectGet(v, k)
+		if !ok {
+			return v
+		}
+		if len(keys) == 1 {
+			return objectDelete(v, k)
+		}
+		return objectSet(v, k, deletePath(child, keys[1:]))
+
+	case int:
+		arr, ok := v.([]interface{})
+		if !ok || k >= len(arr) {
+			return v
+		}
+		if len(keys) == 1 {
+			return append(arr[:k:k], arr[k+1:]...)
+		}
+		arr[k] = deletePath(arr[k], keys[1:])
+		return arr
+	}
+	return v
+}
+
 // recursiveMerge merges maps and slices, or returns b for scalars
 func recursiveMerge(a, b interface{}) (interface{}, error) {
 	switch a.(type) {
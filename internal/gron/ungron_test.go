@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	json "github.com/virtuald/go-ordered-json"
@@ -172,6 +173,70 @@ func TestLex(t *testing.T) {
 			{`1`, TypNumber},
 			{`;`, TypSemi},
 		}},
+
+		{`delete json.foo[0];`, []Token{
+			{`delete`, TypDelete},
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`foo`, TypBare},
+			{`[`, TypLBrace},
+			{`0`, TypNumericKey},
+			{`]`, TypRBrace},
+			{`;`, TypSemi},
+		}},
+
+		{`delete  json["foo"] ;`, []Token{
+			{`delete`, TypDelete},
+			{`json`, TypBare},
+			{`[`, TypLBrace},
+			{`"foo"`, TypQuotedKey},
+			{`]`, TypRBrace},
+			{`;`, TypSemi},
+		}},
+
+		{"delete\tjson.foo;", []Token{
+			{`delete`, TypDelete},
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`foo`, TypBare},
+			{`;`, TypSemi},
+		}},
+
+		{`json.foo = undefined;`, []Token{
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`foo`, TypBare},
+			{`=`, TypEquals},
+			{`undefined`, TypUndefined},
+			{`;`, TypSemi},
+		}},
+
+		{`json.foo = u;`, []Token{
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`foo`, TypBare},
+			{`=`, TypEquals},
+			{`u`, TypError},
+		}},
+
+		{`json.deleted = 1;`, []Token{
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`deleted`, TypBare},
+			{`=`, TypEquals},
+			{`1`, TypNumber},
+			{`;`, TypSemi},
+		}},
+
+		{`json.data = "aGk="; // !!binary`, []Token{
+			{`json`, TypBare},
+			{`.`, TypDot},
+			{`data`, TypBare},
+			{`=`, TypEquals},
+			{`"aGk="`, TypString},
+			{`;`, TypSemi},
+			{`// !!binary`, TypComment},
+		}},
 	}
 
 	for _, c := range cases {
@@ -214,7 +279,7 @@ func TestTokensSimple(t *testing.T) {
 
 	l := newLexer(in)
 	tokens := l.lex()
-	have, err := ungronTokens(tokens)
+	have, err := ungronTokens(tokens, 0)
 	if err != nil {
 		t.Fatalf("failed to ungron statement: %s", err)
 	}
@@ -228,6 +293,21 @@ func TestTokensSimple(t *testing.T) {
 	}
 }
 
+func TestTokensEscapedKey(t *testing.T) {
+	in := `json["a\"b\u00e9"] = 1;`
+	want := json.OrderedObject{
+		{Key: "json", Value: json.OrderedObject{{Key: "a\"bé", Value: json.Number("1")}}},
+	}
+
+	have, err := ungronTokens(newLexer(in).lex(), 0)
+	if err != nil {
+		t.Fatalf("failed to ungron statement: %s", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("want %#v; have %#v", want, have)
+	}
+}
+
 func TestTokensInvalid(t *testing.T) {
 	cases := []struct {
 		in []Token
@@ -240,7 +320,7 @@ func TestTokensInvalid(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		_, err := ungronTokens(c.in)
+		_, err := ungronTokens(c.in, 0)
 		if err == nil {
 			t.Errorf("want non-nil error for %#v; have nil", c.in)
 		}
@@ -371,7 +451,7 @@ func TestUngron(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := Ungron(in, out, false, false)
+		code, err := Ungron(in, out, false, false, UngronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -424,7 +504,7 @@ func TestUngronJ(t *testing.T) {
 		}
 
 		out := &bytes.Buffer{}
-		code, err := Ungron(in, out, true, false)
+		code, err := Ungron(in, out, true, false, UngronOptions{})
 
 		if code != exitOK {
 			t.Errorf("want exitOK; have %d", code)
@@ -447,3 +527,108 @@ func TestUngronJ(t *testing.T) {
 
 	}
 }
+
+func TestUngronOptions(t *testing.T) {
+	in := `json.b = "é";
+json.a = [];
+json.a[0] = 1;
+json.a[1] = "😀";
+`
+	cases := []struct {
+		opts     UngronOptions
+		colorize bool
+		want     string
+	}{
+		{UngronOptions{}, false, "{\n  \"b\": \"é\",\n  \"a\": [\n    1,\n    \"😀\"\n  ]\n}\n"},
+		{UngronOptions{Compact: true}, false, "{\"b\":\"é\",\"a\":[1,\"😀\"]}\n"},
+		{UngronOptions{Indent: "\t"}, false, "{\n\t\"b\": \"é\",\n\t\"a\": [\n\t\t1,\n\t\t\"😀\"\n\t]\n}\n"},
+		{UngronOptions{Indent: "    ", SortKeys: true}, false, "{\n    \"a\": [\n        1,\n        \"😀\"\n    ],\n    \"b\": \"é\"\n}\n"},
+		{UngronOptions{Compact: true, ASCII: true}, false, "{\"b\":\"\\u00e9\",\"a\":[1,\"\\ud83d\\ude00\"]}\n"},
+		{UngronOptions{Compact: true, ASCII: true}, true, "{\"b\":\"\\u00e9\",\"a\":[1,\"\\ud83d\\ude00\"]}\n"},
+		{UngronOptions{YAML: true}, false, "b: é\na:\n  - 1\n  - \"\\U0001F600\"\n"},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := Ungron(bytes.NewBufferString(in), out, false, c.colorize, c.opts)
+
+		if code != exitOK {
+			t.Errorf("want exitOK; have %d", code)
+		}
+		if err != nil {
+			t.Errorf("want nil error; have %s", err)
+		}
+
+		if out.String() != c.want {
+			t.Logf("want: %s", c.want)
+			t.Logf("have: %s", out.String())
+			t.Errorf("ungronned output does not match for %#v with colorize %t", c.opts, c.colorize)
+		}
+	}
+}
+
+func TestUngronLines(t *testing.T) {
+	in, err := os.Open("testdata/stream.gron")
+	if err != nil {
+		t.Fatalf("failed to open input file: %s", err)
+	}
+
+	out := &bytes.Buffer{}
+	code, err := Ungron(in, out, false, false, UngronOptions{Lines: true, SortKeys: true})
+
+	if code != exitOK {
+		t.Errorf("want exitOK; have %d", code)
+	}
+	if err != nil {
+		t.Errorf("want nil error; have %s", err)
+	}
+
+	want := "{\"one\":1,\"three\":[1,2,3],\"two\":2}\n{\"one\":1,\"three\":[1,2,3],\"two\":2}\n"
+	if out.String() != want {
+		t.Logf("want: %s", want)
+		t.Logf("have: %s", out.String())
+		t.Errorf("ungronned JSON Lines do not match")
+	}
+}
+
+func TestUngronLinesFiltered(t *testing.T) {
+	in := "json[2] = {};\njson[2].id = 3;\njson[4] = null;\n"
+
+	out := &bytes.Buffer{}
+	code, err := Ungron(strings.NewReader(in), out, false, false, UngronOptions{Lines: true})
+
+	if code != exitOK {
+		t.Errorf("want exitOK; have %d", code)
+	}
+	if err != nil {
+		t.Errorf("want nil error; have %s", err)
+	}
+
+	want := "{\"id\":3}\nnull\n"
+	if out.String() != want {
+		t.Logf("want: %s", want)
+		t.Logf("have: %s", out.String())
+		t.Errorf("ungronned JSON Lines do not match")
+	}
+}
+
+func TestUngronConflictWarnings(t *testing.T) {
+	in := "json = {};\njson.a = 1;\njson.a = 2;\njson.a = {};\njson.a = 3;\n"
+
+	out := &bytes.Buffer{}
+	warnings := &bytes.Buffer{}
+	code, err := Ungron(strings.NewReader(in), out, false, false, UngronOptions{Warnings: warnings})
+
+	if code != exitParseStatements {
+		t.Errorf("want exitParseStatements; have %d", code)
+	}
+	if err == nil || err.Error() != "line 5: json.a was already assigned a different value on line 4" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	want := "warning: line 3: json.a was already assigned a different value on line 2\n" +
+		"warning: line 4: json.a was already assigned a different value on line 3\n"
+	if warnings.String() != want {
+		t.Errorf("want warnings %q; have %q", want, warnings.String())
+	}
+}
@@ -0,0 +1,149 @@
+package gron
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// A violation is a part of a document that doesn't match a JSON Schema
+type violation struct {
+	// Pointer is the JSON Pointer of the value; e.g. /items/3/price
+	Pointer string
+
+	// Message describes what's wrong with the value
+	Message string
+}
+
+// compileSchema compiles a JSON Schema read from r. Schemas that don't
+// give their dialect with $schema are read as draft 2020-12
+func compileSchema(r io.Reader, url string) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	err := c.AddResource(url, r)
+	if err != nil {
+		return nil, err
+	}
+	return c.Compile(url)
+}
+
+// schemaViolations returns the violations of a JSON Schema by a value
+func schemaViolations(schema *jsonschema.Schema, v interface{}) ([]violation, error) {
+	// The validator needs the types that encoding/json decodes into
+	j, err := encodeJSON(v, UngronOptions{Compact: true})
+	if err != nil {
+		return nil, err
+	}
+	d := stdjson.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	var doc interface{}
+	err = d.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(doc)
+	if err == nil {
+		return nil, nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	var out []violation
+	var leaves func(*jsonschema.ValidationError)
+	leaves = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			out = append(out, violation{Pointer: e.InstanceLocation, Message: violationMessage(e.Message)})
+			return
+		}
+		for _, c := range e.Causes {
+			leaves(c)
+		}
+	}
+	leaves(ve)
+	return out, nil
+}
+
+// violationMessage shortens a validator's message where the statement
+// it's written next to already says the rest.
+// E.g:
+//
+//	expected number, but got string -> expected number
+func violationMessage(msg string) string {
+	if i := strings.Index(msg, ", but got "); i != -1 && strings.HasPrefix(msg, "expected ") {
+		return msg[:i]
+	}
+	return msg
+}
+
+// ValidateSchema checks a JSON or YAML document against a JSON Schema and
+// writes the statement for each value that doesn't match it, with a
+// comment saying why. With all set every statement of the document is
+// written. It returns exitInvalid if there were any violations
+// E.g:
+//
+//	json.items[3].price = "12"; // expected number
+func ValidateSchema(
+	schema io.Reader,
+	schemaURL string,
+	doc io.Reader,
+	w io.Writer,
+	conv StatementConv,
+	inYaml bool,
+	all bool,
+) (int, error) {
+	compiled, err := compileSchema(schema, schemaURL)
+	if err != nil {
+		return exitReadInput, errors.Wrap(err, "failed to read schema")
+	}
+
+	var v interface{}
+	err = MakeDecoder(doc, inputFormat(inYaml), false).Decode(&v)
+	if err != nil {
+		return exitReadInput, fmt.Errorf("failed to read document: %s", err)
+	}
+
+	violations, err := schemaViolations(compiled, v)
+	if err != nil {
+		return exitReadInput, errors.Wrap(err, "failed to validate document")
+	}
+
+	ss := make(Statements, 0, 32)
+	ss.fill(Statement{{"json", TypBare}}, v)
+
+	// Violations are written next to the statement for their value
+	index := make(map[string]int, len(ss))
+	for i, s := range ss {
+		keys, err := s.Path()
+		if err == nil {
+			index[jsonPointer(keys)] = i
+		}
+	}
+	violated := make(map[int]bool)
+	for _, vi := range violations {
+		i, ok := index[vi.Pointer]
+		if !ok {
+			i = 0
+		}
+		ss[i] = ss[i].withComment(vi.Message)
+		violated[i] = true
+	}
+
+	for i, s := range ss {
+		if all || violated[i] {
+			fmt.Fprintln(w, conv(s))
+		}
+	}
+
+	if len(violations) > 0 {
+		return exitInvalid, fmt.Errorf("document has %d schema violations", len(violations))
+	}
+	return exitOK, nil
+}
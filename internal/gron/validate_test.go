@@ -0,0 +1,75 @@
+package gron
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {"price": {"type": "number"}},
+					"required": ["price"]
+				}
+			}
+		}
+	}`
+
+	cases := []struct {
+		doc  string
+		all  bool
+		code int
+		want []string
+	}{
+		{
+			`{"id":0,"items":[{"price":1},{"price":"12"},{}]}`,
+			false,
+			exitInvalid,
+			[]string{
+				`json.id = 0; // must be >= 1 but found 0`,
+				`json.items[1].price = "12"; // expected number`,
+				`json.items[2] = {}; // missing properties: 'price'`,
+			},
+		},
+		{
+			`{"id":2,"items":[{"price":"12"}]}`,
+			true,
+			exitInvalid,
+			[]string{
+				`json = {};`,
+				`json.id = 2;`,
+				`json.items = [];`,
+				`json.items[0] = {};`,
+				`json.items[0].price = "12"; // expected number`,
+			},
+		},
+		{`{"id":1}`, false, exitOK, nil},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, _ := ValidateSchema(strings.NewReader(schema), "schema.json", strings.NewReader(c.doc), out, StatementToString, false, c.all)
+		if code != c.code {
+			t.Errorf("want exit code %d for %s; have %d", c.code, c.doc, code)
+		}
+
+		have := strings.TrimSpace(out.String())
+		if have != strings.Join(c.want, "\n") {
+			t.Errorf("want:\n%s\nhave:\n%s", strings.Join(c.want, "\n"), have)
+		}
+	}
+}
+
+func TestValidateSchemaInvalidSchema(t *testing.T) {
+	_, err := ValidateSchema(strings.NewReader(`{"type": 5}`), "schema.json", strings.NewReader(`{}`), &bytes.Buffer{}, StatementToString, false, false)
+	if err == nil {
+		t.Errorf("want error for an invalid schema")
+	}
+}
@@ -0,0 +1,286 @@
+package gron
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+)
+
+// XML is read into objects keyed by element name. The attributes of an
+// element are keys starting with xmlAttrPrefix, and its text is the value
+// of xmlTextKey. Elements with neither attributes nor child elements are
+// just their text, and repeated elements are arrays.
+// E.g:
+//
+//	<user id="1"><name>Tom</name><tag>a</tag><tag>b</tag></user>
+//
+//	json.user["@id"] = "1";
+//	json.user.name = "Tom";
+//	json.user.tag[0] = "a";
+//	json.user.tag[1] = "b";
+//
+// The mapping loses some things. The text of an element with child
+// elements is joined into one value, so <p>a<b>x</b>c</p> is written
+// back as <p><b>x</b>ac</p>. Comments, processing instructions and
+// the order of differently named child elements are also lost.
+// Writing XML, an empty array is no elements at all, and keys that
+// aren't XML names, arrays of arrays and attributes holding objects or
+// arrays are an error
+const (
+	xmlAttrPrefix = "@"
+	xmlTextKey    = "#text"
+)
+
+// xmlRootName is the name of the element that values are written in
+// when they aren't an object with a single element
+const xmlRootName = "root"
+
+// xmlItemName is the name of the elements that the elements of a
+// top-level array are written in, inside an element named xmlRootName
+const xmlItemName = "item"
+
+// An xmlDecoder reads an XML document as an object with a single key,
+// the name of the document element
+type xmlDecoder struct {
+	d    *xml.Decoder
+	done bool
+}
+
+// newXMLDecoder returns an xmlDecoder for r
+func newXMLDecoder(r io.Reader) *xmlDecoder {
+	return &xmlDecoder{d: xml.NewDecoder(r)}
+}
+
+// xmlName returns the name of an element or attribute as written,
+// with its namespace prefix if it has one; e.g. soap:Envelope
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// Decode reads the XML document into v, which must be an *interface{}.
+// There's only one document element, so later calls return io.EOF
+func (d *xmlDecoder) Decode(v interface{}) error {
+	out, ok := v.(*interface{})
+	if !ok {
+		return errors.New("XML can only be decoded into an interface{}")
+	}
+	if d.done {
+		return io.EOF
+	}
+
+	for {
+		t, err := d.d.RawToken()
+		if err == io.EOF && !d.done {
+			return errors.New("no XML element found")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Everything outside of the document element but
+		// the element itself is left out
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		d.done = true
+		value, err := d.element(start)
+		if err != nil {
+			return err
+		}
+		*out = json.OrderedObject{{Key: xmlName(start.Name), Value: value}}
+		return nil
+	}
+}
+
+// element reads the content of an element up to its end tag
+func (d *xmlDecoder) element(start xml.StartElement) (interface{}, error) {
+	obj := json.OrderedObject{}
+	for _, a := range start.Attr {
+		obj = append(obj, json.Member{Key: xmlAttrPrefix + xmlName(a.Name), Value: a.Value})
+	}
+
+	var text strings.Builder
+	for {
+		t, err := d.d.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("element <%s> is not closed", xmlName(start.Name))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tt := t.(type) {
+		case xml.StartElement:
+			child, err := d.element(tt)
+			if err != nil {
+				return nil, err
+			}
+			obj = addXMLChild(obj, xmlName(tt.Name), child)
+		case xml.CharData:
+			text.Write(tt)
+		case xml.EndElement:
+			if tt.Name != start.Name {
+				return nil, fmt.Errorf("element <%s> is closed by </%s>", xmlName(start.Name), xmlName(tt.Name))
+			}
+			trimmed := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				obj = append(obj, json.Member{Key: xmlTextKey, Value: trimmed})
+			}
+			return obj, nil
+		}
+	}
+}
+
+// addXMLChild adds a child element to an object, turning the value
+// into an array if there's already an element with the same name
+func addXMLChild(obj json.OrderedObject, name string, v interface{}) json.OrderedObject {
+	for i, m := range obj {
+		if m.Key != name {
+			continue
+		}
+		if arr, ok := m.Value.([]interface{}); ok {
+			obj[i].Value = append(arr, v)
+		} else {
+			obj[i].Value = []interface{}{m.Value, v}
+		}
+		return obj
+	}
+	return append(obj, json.Member{Key: name, Value: v})
+}
+
+// xmlText returns the text of a scalar value as written in XML
+func xmlText(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	default:
+		return valueTokenFromInterface(v).Text
+	}
+}
+
+// encodeXML writes a value as an XML document. An object with a single
+// key that isn't an attribute or text is the document element; anything
+// else is written in an element named xmlRootName. The elements of an
+// array are each written in an element named xmlItemName
+func encodeXML(w io.Writer, v interface{}, opts UngronOptions) error {
+	if opts.SortKeys {
+		v = sortKeys(v)
+	}
+
+	name, value := xmlRootName, v
+	if arr, ok := v.([]interface{}); ok {
+		value = json.OrderedObject{{Key: xmlItemName, Value: arr}}
+	}
+	if obj, ok := v.(json.OrderedObject); ok && len(obj) == 1 {
+		_, isArray := obj[0].Value.([]interface{})
+		if !isArray && !strings.HasPrefix(obj[0].Key, xmlAttrPrefix) && obj[0].Key != xmlTextKey {
+			name, value = obj[0].Key, obj[0].Value
+		}
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", opts.indent())
+	err := encodeXMLElement(enc, name, value)
+	if err != nil {
+		return err
+	}
+	err = enc.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// validXMLName returns true if a name can be written as the name of
+// an element or attribute, with or without a namespace prefix
+func validXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == ':' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// encodeXMLElement writes a value as an element with a name, or as an
+// element for each of its values if it's an array
+func encodeXMLElement(enc *xml.Encoder, name string, v interface{}) error {
+	if !validXMLName(name) {
+		return fmt.Errorf("`%s` is not a valid XML element name", name)
+	}
+	if arr, ok := v.([]interface{}); ok {
+		for _, e := range arr {
+			if _, nested := e.([]interface{}); nested {
+				return fmt.Errorf("arrays of arrays can't be written as XML, as in <%s>", name)
+			}
+			err := encodeXMLElement(enc, name, e)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	obj, isObject := v.(json.OrderedObject)
+	for _, m := range obj {
+		if strings.HasPrefix(m.Key, xmlAttrPrefix) {
+			attr := strings.TrimPrefix(m.Key, xmlAttrPrefix)
+			if !validXMLName(attr) {
+				return fmt.Errorf("`%s` is not a valid XML attribute name in <%s>", attr, name)
+			}
+			switch m.Value.(type) {
+			case json.OrderedObject, []interface{}:
+				return fmt.Errorf("attribute `%s` of <%s> holds an object or array, which XML attributes can't", attr, name)
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: xmlText(m.Value)})
+		}
+	}
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write <%s>", name)
+	}
+
+	if isObject {
+		for _, m := range obj {
+			switch {
+			case strings.HasPrefix(m.Key, xmlAttrPrefix):
+			case m.Key == xmlTextKey:
+				err = enc.EncodeToken(xml.CharData(xmlText(m.Value)))
+			default:
+				err = encodeXMLElement(enc, m.Key, m.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	} else if text := xmlText(v); text != "" {
+		err = enc.EncodeToken(xml.CharData(text))
+		if err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
@@ -0,0 +1,151 @@
+package gron
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGronXML(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{`<?xml version="1.0"?><!-- c --><user id="1"><name>Tom &amp; co</name><tag>a</tag><tag>b</tag><empty/></user>`, `json = {};
+json.user = {};
+json.user["@id"] = "1";
+json.user.name = "Tom & co";
+json.user.tag = [];
+json.user.tag[0] = "a";
+json.user.tag[1] = "b";
+json.user.empty = "";
+`},
+		{`<note lang="en">  Hello <![CDATA[<there>]]>  </note>`, `json = {};
+json.note = {};
+json.note["@lang"] = "en";
+json.note["#text"] = "Hello <there>";
+`},
+		{`<s:a xmlns:s="urn:x"><s:b>1</s:b></s:a>`, `json = {};
+json["s:a"] = {};
+json["s:a"]["@xmlns:s"] = "urn:x";
+json["s:a"]["s:b"] = "1";
+`},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := Gron(strings.NewReader(c.in), out, StatementToString, InputXML, false, false, GronOptions{})
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to gron %s: %d, %s", c.in, code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%s\nhave:\n%s", c.want, out.String())
+		}
+	}
+}
+
+func TestGronXMLErrors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`<!-- nothing -->`,
+		`<a><b></a>`,
+		`<a><b></b>`,
+	} {
+		code, err := Gron(strings.NewReader(in), &bytes.Buffer{}, StatementToString, InputXML, false, false, GronOptions{})
+		if code == exitOK || err == nil {
+			t.Errorf("want error for %q; have none", in)
+		}
+	}
+}
+
+func TestUngronXML(t *testing.T) {
+	cases := []struct {
+		in   string
+		opts UngronOptions
+		want string
+	}{
+		{`json.user["@id"] = "1";
+json.user.name = "Tom & co";
+json.user.tag[0] = "a";
+json.user.tag[1] = "b";
+json.user.note["#text"] = "Hi";
+json.user.note["@lang"] = "en";
+json.user.age = 30;
+json.user.gone = null;
+`, UngronOptions{XML: true}, `<user id="1">
+  <name>Tom &amp; co</name>
+  <tag>a</tag>
+  <tag>b</tag>
+  <note lang="en">Hi</note>
+  <age>30</age>
+  <gone></gone>
+</user>
+`},
+		{`json.a = 1;
+json.b = true;
+`, UngronOptions{XML: true, Compact: true}, "<root><a>1</a><b>true</b></root>\n"},
+		{`json[0] = "x";
+json[1] = "y";
+`, UngronOptions{XML: true, Indent: "\t"}, "<root>\n\t<item>x</item>\n\t<item>y</item>\n</root>\n"},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		code, err := Ungron(strings.NewReader(c.in), out, false, false, c.opts)
+		if code != exitOK || err != nil {
+			t.Fatalf("failed to ungron %s: %d, %s", c.in, code, err)
+		}
+		if out.String() != c.want {
+			t.Errorf("want:\n%s\nhave:\n%s", c.want, out.String())
+		}
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	in := `<feed xmlns:x="urn:x">
+  <entry id="1">
+    <title>One &lt;1&gt;</title>
+    <x:tag>a</x:tag>
+    <x:tag>b</x:tag>
+  </entry>
+  <entry id="2">
+    <title lang="en">Two</title>
+  </entry>
+</feed>
+`
+
+	gronned := &bytes.Buffer{}
+	code, err := Gron(strings.NewReader(in), gronned, StatementToString, InputXML, false, false, GronOptions{})
+	if code != exitOK || err != nil {
+		t.Fatalf("failed to gron: %d, %s", code, err)
+	}
+
+	out := &bytes.Buffer{}
+	code, err = Ungron(gronned, out, false, false, UngronOptions{XML: true})
+	if code != exitOK || err != nil {
+		t.Fatalf("failed to ungron: %d, %s", code, err)
+	}
+	if out.String() != in {
+		t.Errorf("want:\n%s\nhave:\n%s", in, out.String())
+	}
+}
+
+func TestUngronXMLErrors(t *testing.T) {
+	for _, in := range []string{
+		`json["first name"] = "x";`,
+		`json.a["0"] = 1;`,
+		`json.a["@b c"] = "x";`,
+		`json.a["@b"] = {};
+json.a["@b"].c = 1;`,
+		`json.a["@b"] = [];`,
+		`json.a = [];
+json.a[0] = [];
+json.a[0][0] = 1;`,
+	} {
+		code, err := Ungron(strings.NewReader(in), io.Discard, false, false, UngronOptions{XML: true})
+		if code != exitJSONEncode || err == nil {
+			t.Errorf("want exitJSONEncode and an error for %s; have %d and %v", in, code, err)
+		}
+	}
+}
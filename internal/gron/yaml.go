@@ -0,0 +1,755 @@
+package gron
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDecoder decodes YAML documents into the same ordered
+// types used for JSON so that the order of keys is preserved
+type yamlDecoder struct {
+	d *yaml.Decoder
+
+	// doc holds the node of the last document decoded, so that it
+	// can be edited without losing its comments and styles
+	doc *yaml.Node
+
+	// tags holds the tags of the last document decoded
+	tags *yamlTags
+}
+
+// newYAMLDecoder returns a yamlDecoder reading from r
+func newYAMLDecoder(r io.Reader) *yamlDecoder {
+	return &yamlDecoder{d: yaml.NewDecoder(r)}
+}
+
+// Decode reads the next YAML document into v, which should
+// be a pointer to an empty interface
+func (d *yamlDecoder) Decode(v interface{}) error {
+	var n yaml.Node
+	err := d.d.Decode(&n)
+	if err != nil {
+		return err
+	}
+
+	out, ok := v.(*interface{})
+	if !ok {
+		return n.Decode(v)
+	}
+	c := newYAMLConverter()
+	*out, err = c.value(&n, nil)
+	d.doc = &n
+	d.tags = c.tags
+	return err
+}
+
+// yamlTags holds the YAML tags that the gron form of a document doesn't
+// imply, by JSON Pointer: those of values, and those of mapping keys that
+// aren't strings; e.g. the !!int of 1: one
+type yamlTags struct {
+	values map[string]string
+	keys   map[string]string
+}
+
+// newYAMLTags returns an empty yamlTags
+func newYAMLTags() *yamlTags {
+	return &yamlTags{values: make(map[string]string), keys: make(map[string]string)}
+}
+
+// empty reports whether there are no tags, which a nil *yamlTags has
+func (t *yamlTags) empty() bool {
+	return t == nil || len(t.values)+len(t.keys) == 0
+}
+
+// copyUnder copies the tags at and beneath a path from another yamlTags
+func (t *yamlTags) copyUnder(from *yamlTags, path []interface{}) {
+	p := jsonPointer(path)
+	under := func(k string) bool {
+		return k == p || strings.HasPrefix(k, p+"/")
+	}
+	for k, tag := range from.values {
+		if under(k) {
+			t.values[k] = tag
+		}
+	}
+	for k, tag := range from.keys {
+		if under(k) {
+			t.keys[k] = tag
+		}
+	}
+}
+
+// maxYAMLAliasNodes is the most nodes that aliases may expand into in a
+// single document, so that documents like the billion laughs can't use
+// up all memory
+const maxYAMLAliasNodes = 1000000
+
+// yamlAliases tracks the expansion of aliases in a document
+type yamlAliases struct {
+	// expanding holds the anchored nodes whose aliases are being expanded
+	expanding map[*yaml.Node]bool
+
+	// nodes counts the nodes converted while expanding aliases
+	nodes int
+}
+
+// yamlConverter converts YAML nodes into values, using json.OrderedObject
+// for mappings to keep the order of keys. It notes the tags that the
+// values and keys don't imply
+type yamlConverter struct {
+	tags    *yamlTags
+	aliases *yamlAliases
+}
+
+// newYAMLConverter returns a yamlConverter with no tags noted
+func newYAMLConverter() *yamlConverter {
+	return &yamlConverter{
+		tags:    newYAMLTags(),
+		aliases: &yamlAliases{expanding: make(map[*yaml.Node]bool)},
+	}
+}
+
+// sub returns a yamlConverter with no tags noted that shares the
+// alias expansion of c
+func (c *yamlConverter) sub() *yamlConverter {
+	return &yamlConverter{tags: newYAMLTags(), aliases: c.aliases}
+}
+
+// enterAlias marks the node an alias refers to as being expanded. It
+// returns an error if the node is already being expanded, which would
+// never end, or if aliases have expanded into too many nodes
+func (c *yamlConverter) enterAlias(n *yaml.Node) error {
+	if c.aliases.expanding[n.Alias] {
+		return fmt.Errorf("alias *%s on line %d refers to itself", n.Value, n.Line)
+	}
+	c.aliases.expanding[n.Alias] = true
+	return nil
+}
+
+// leaveAlias marks the node an alias refers to as no longer being expanded
+func (c *yamlConverter) leaveAlias(n *yaml.Node) {
+	delete(c.aliases.expanding, n.Alias)
+}
+
+// count notes that a node is being converted, returning an error if it's
+// one too many of those expanded from aliases
+func (c *yamlConverter) count() error {
+	if len(c.aliases.expanding) == 0 {
+		return nil
+	}
+	c.aliases.nodes++
+	if c.aliases.nodes > maxYAMLAliasNodes {
+		return fmt.Errorf("aliases expand into more than %d nodes", maxYAMLAliasNodes)
+	}
+	return nil
+}
+
+// value converts a YAML node at a path into a value
+func (c *yamlConverter) value(n *yaml.Node, path []interface{}) (interface{}, error) {
+	var out interface{}
+	err := c.count()
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return c.value(n.Content[0], path)
+
+	case yaml.AliasNode:
+		err = c.enterAlias(n)
+		if err != nil {
+			return nil, err
+		}
+		defer c.leaveAlias(n)
+		return c.value(n.Alias, path)
+
+	case yaml.SequenceNode:
+		elems := make([]interface{}, 0, len(n.Content))
+		for i, child := range n.Content {
+			v, err := c.value(child, appendKey(path, i))
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, v)
+		}
+		out = elems
+
+	case yaml.MappingNode:
+		out, err = c.mapping(n, path)
+
+	case yaml.ScalarNode:
+		out, err = yamlScalar(n)
+
+	default:
+		return nil, fmt.Errorf("unexpected YAML node on line %d", n.Line)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if tag := n.ShortTag(); tag != impliedTag(out) {
+		c.tags.values[jsonPointer(path)] = tag
+	}
+	return out, nil
+}
+
+// appendKey returns a copy of a path with a key appended to it
+func appendKey(path []interface{}, k interface{}) []interface{} {
+	out := make([]interface{}, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, k)
+}
+
+// yamlScalar converts a YAML scalar into a value. Timestamps and binary
+// data keep the text they were written with, and scalars with custom
+// tags are read as though they had no tag
+func yamlScalar(n *yaml.Node) (interface{}, error) {
+	plain := *n
+	if !strings.HasPrefix(n.ShortTag(), "!!") {
+		plain.Tag = ""
+	}
+
+	switch plain.ShortTag() {
+	case "!!int", "!!float":
+		return yamlNumber(&plain)
+	case "!!timestamp":
+		return n.Value, nil
+	case "!!binary":
+		return strings.Join(strings.Fields(n.Value), ""), nil
+	}
+
+	var v interface{}
+	err := plain.Decode(&v)
+	if err != nil {
+		// Values with tags that yaml.v3 doesn't know keep their text
+		return n.Value, nil
+	}
+	return v, nil
+}
+
+// impliedTag returns the YAML tag that a value's gron form implies
+func impliedTag(v interface{}) string {
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		return "!!map"
+	case []interface{}:
+		return "!!seq"
+	case json.Number:
+		if strings.ContainsAny(vv.String(), ".eE") {
+			return "!!float"
+		}
+		return "!!int"
+	case string:
+		return "!!str"
+	case bool:
+		return "!!bool"
+	case nil:
+		return "!!null"
+	default:
+		return ""
+	}
+}
+
+// jsonNumber matches numbers written the way JSON requires
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// yamlNumber returns a YAML number as a json.Number with the text it
+// was written with, so that large integers and precise decimals aren't
+// rounded. Numbers written in forms that JSON doesn't allow are
+// converted; e.g. 0x1F to 31 and +.5 to 0.5. Infinity and NaN have no
+// JSON form so they're kept as strings
+func yamlNumber(n *yaml.Node) (interface{}, error) {
+	text := strings.ReplaceAll(n.Value, "_", "")
+	if jsonNumber.MatchString(text) {
+		return json.Number(text), nil
+	}
+
+	if n.ShortTag() == "!!int" {
+		i, ok := new(big.Int).SetString(text, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer `%s` on line %d", n.Value, n.Line)
+		}
+		return json.Number(i.String()), nil
+	}
+
+	switch strings.ToLower(strings.TrimLeft(text, "+-")) {
+	case ".inf", ".nan":
+		return n.Value, nil
+	}
+
+	// Fill in the digits that JSON needs around the decimal point
+	sign := ""
+	if text[0] == '-' || text[0] == '+' {
+		if text[0] == '-' {
+			sign = "-"
+		}
+		text = text[1:]
+	}
+	if strings.HasPrefix(text, ".") {
+		text = "0" + text
+	}
+	text = strings.Replace(text, ".e", ".0e", 1)
+	text = strings.Replace(text, ".E", ".0E", 1)
+	if strings.HasSuffix(text, ".") {
+		text += "0"
+	}
+	text = strings.TrimLeft(text, "0")
+	if text == "" || text[0] == '.' || text[0] == 'e' || text[0] == 'E' {
+		text = "0" + text
+	}
+	if !jsonNumber.MatchString(sign + text) {
+		return nil, fmt.Errorf("invalid number `%s` on line %d", n.Value, n.Line)
+	}
+	return json.Number(sign + text), nil
+}
+
+// mapping converts a YAML mapping node into an ordered object.
+// Mappings included with the merge key (<<) contribute the keys that
+// the mapping doesn't define itself, in the position of the merge key
+func (c *yamlConverter) mapping(n *yaml.Node, path []interface{}) (json.OrderedObject, error) {
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].ShortTag() != "!!merge" {
+			explicit[c.key(n.Content[i])] = true
+		}
+	}
+
+	out := make(json.OrderedObject, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+
+		if k.ShortTag() == "!!merge" {
+			sub := c.sub()
+			merged, err := sub.mergedMembers(v, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range merged {
+				if _, exists := objectGet(out, m.Key); !explicit[m.Key] && !exists {
+					out = append(out, m)
+					c.tags.copyUnder(sub.tags, appendKey(path, m.Key))
+				}
+			}
+			continue
+		}
+
+		key := c.key(k)
+		val, err := c.value(v, appendKey(path, key))
+		if err != nil {
+			return nil, err
+		}
+		if tag := yamlKeyTag(k); tag != "!!str" {
+			c.tags.keys[jsonPointer(appendKey(path, key))] = tag
+		}
+		out = objectSet(out, key, val).(json.OrderedObject)
+	}
+	return out, nil
+}
+
+// mergedMembers returns the members contributed by the value of a merge
+// key: a mapping or a sequence of mappings, earlier ones taking precedence
+func (c *yamlConverter) mergedMembers(n *yaml.Node, path []interface{}) (json.OrderedObject, error) {
+	if n.Kind == yaml.AliasNode {
+		err := c.enterAlias(n)
+		if err != nil {
+			return nil, err
+		}
+		defer c.leaveAlias(n)
+		return c.mergedMembers(n.Alias, path)
+	}
+	err := c.count()
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		return c.mapping(n, path)
+
+	case yaml.SequenceNode:
+		out := json.OrderedObject{}
+		for _, child := range n.Content {
+			sub := c.sub()
+			members, err := sub.mergedMembers(child, path)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range members {
+				if _, exists := objectGet(out, m.Key); !exists {
+					out = append(out, m)
+					c.tags.copyUnder(sub.tags, appendKey(path, m.Key))
+				}
+			}
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("merge key on line %d must refer to a mapping", n.Line)
+	}
+}
+
+// key returns the string form of a mapping key. Scalar keys of any
+// type are used as written; e.g. 1, true or 2001-12-14. Keys that are
+// mappings or sequences are written as compact JSON
+func (c *yamlConverter) key(n *yaml.Node) string {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+
+	v, err := c.sub().value(n, nil)
+	if err != nil {
+		return n.Value
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return n.Value
+	}
+	return string(j)
+}
+
+// yamlKeyTag returns the tag of a scalar mapping key, which can be
+// restored when ungronning. Keys that are mappings or sequences are
+// left as strings
+func yamlKeyTag(n *yaml.Node) string {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "!!str"
+	}
+	return n.ShortTag()
+}
+
+// interfaceToNode converts a value at a path into a YAML node, keeping
+// the order of keys in ordered objects and the text of numbers. Values
+// and keys are given the tags found for their path in tags
+func interfaceToNode(v interface{}, tags *yamlTags, path []interface{}) (*yaml.Node, error) {
+	n, err := untaggedNode(v, tags, path)
+	if err != nil || tags.empty() {
+		return n, err
+	}
+
+	if tag, ok := tags.values[jsonPointer(path)]; ok {
+		retag(n, tag)
+	}
+	return n, nil
+}
+
+// retag gives a node a tag, letting the encoder decide whether a
+// scalar needs quoting under its new tag
+func retag(n *yaml.Node, tag string) {
+	n.Tag = tag
+	if n.Kind == yaml.ScalarNode {
+		n.Style = 0
+	}
+}
+
+// untaggedNode converts a value into a YAML node with its default tag
+func untaggedNode(v interface{}, tags *yamlTags, path []interface{}) (*yaml.Node, error) {
+	switch vv := v.(type) {
+	case json.OrderedObject:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, m := range vv {
+			k := &yaml.Node{}
+			k.SetString(m.Key)
+			if !tags.empty() {
+				if tag, ok := tags.keys[jsonPointer(appendKey(path, m.Key))]; ok {
+					retag(k, tag)
+				}
+			}
+			sub, err := interfaceToNode(m.Value, tags, appendKey(path, m.Key))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, k, sub)
+		}
+		return n, nil
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		o := make(json.OrderedObject, 0, len(vv))
+		for _, k := range keys {
+			o = append(o, json.Member{Key: k, Value: vv[k]})
+		}
+		return untaggedNode(o, tags, path)
+
+	case []interface{}:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, e := range vv {
+			sub, err := interfaceToNode(e, tags, appendKey(path, i))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, sub)
+		}
+		return n, nil
+
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(vv.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: vv.String()}, nil
+
+	default:
+		n := &yaml.Node{}
+		err := n.Encode(v)
+		return n, err
+	}
+}
+
+// encodeYAML marshals a value into a YAML document
+func encodeYAML(w io.Writer, v interface{}) error {
+	return encodeYAMLWithTags(w, v, nil)
+}
+
+// encodeYAMLWithTags marshals a value into a YAML document, giving
+// values and keys the tags found for their JSON Pointer in tags
+func encodeYAMLWithTags(w io.Writer, v interface{}, tags *yamlTags) error {
+	n, err := interfaceToNode(v, tags, nil)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	err = enc.Encode(n)
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeYAMLEdit writes the YAML document that a decoded document became
+// after being edited. The differences between the two are applied to the
+// document's node, so that the comments, styles and tags of everything
+// that wasn't changed are kept. New values are given the tags found for
+// their path in tags. A nil node, for an empty document, is written from
+// the value alone
+func writeYAMLEdit(w io.Writer, doc *yaml.Node, before, after interface{}, tags *yamlTags) error {
+	if doc == nil {
+		return encodeYAMLWithTags(w, after, tags)
+	}
+
+	patch, err := DiffPatch(before, after)
+	if err != nil {
+		return err
+	}
+	err = patchYAMLNode(doc, patch, tags)
+	if err != nil {
+		return err
+	}
+	untagMergeKeys(doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// untagMergeKeys clears the tag of the merge keys beneath a node, which
+// the encoder would otherwise write out as !!merge <<
+func untagMergeKeys(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].ShortTag() == "!!merge" {
+				n.Content[i].Tag = ""
+			}
+		}
+	}
+	for _, child := range n.Content {
+		untagMergeKeys(child)
+	}
+}
+
+// patchYAMLNode applies the add, remove and replace operations of a
+// JSON Patch to a YAML document node. Values can't be changed beneath
+// an alias, which would change every other use of its anchor too
+func patchYAMLNode(doc *yaml.Node, patch JSONPatch, tags *yamlTags) error {
+	for _, op := range patch {
+		tokens, err := parseJSONPointer(op.Path)
+		if err != nil {
+			return err
+		}
+
+		if len(tokens) == 0 {
+			if op.Op == "remove" {
+				return errors.New("cannot remove the whole document")
+			}
+			n, err := interfaceToNode(op.Value, tags, nil)
+			if err != nil {
+				return err
+			}
+			doc.Content = []*yaml.Node{n}
+			continue
+		}
+
+		parent, keys, err := yamlNodeAt(doc, tokens[:len(tokens)-1])
+		if err != nil {
+			return errors.Wrapf(err, "cannot %s `%s`", op.Op, op.Path)
+		}
+		last := tokens[len(tokens)-1]
+
+		switch parent.Kind {
+		case yaml.MappingNode:
+			err = patchYAMLMapping(parent, op, last, keys, tags)
+		case yaml.SequenceNode:
+			err = patchYAMLSequence(parent, op, last, keys, tags)
+		default:
+			err = fmt.Errorf("the value on line %d holds no other values", parent.Line)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot %s `%s`", op.Op, op.Path)
+		}
+	}
+	return nil
+}
+
+// yamlNodeAt returns the node that the reference tokens of a JSON
+// Pointer refer to beneath a document node, along with its path
+func yamlNodeAt(doc *yaml.Node, tokens []string) (*yaml.Node, []interface{}, error) {
+	if len(doc.Content) == 0 {
+		return nil, nil, errors.New("the document is empty")
+	}
+	n := doc.Content[0]
+	keys := make([]interface{}, 0, len(tokens))
+	for _, t := range tokens {
+		switch n.Kind {
+		case yaml.AliasNode:
+			return nil, nil, fmt.Errorf("the value on line %d is an alias", n.Line)
+
+		case yaml.MappingNode:
+			i := yamlMappingIndex(n, t)
+			if i < 0 {
+				return nil, nil, fmt.Errorf("`%s` comes from a merge key", t)
+			}
+			n = n.Content[i+1]
+			keys = append(keys, t)
+
+		case yaml.SequenceNode:
+			i, err := arrayIndex(t, len(n.Content), false)
+			if err != nil {
+				return nil, nil, err
+			}
+			n = n.Content[i]
+			keys = append(keys, i)
+
+		default:
+			return nil, nil, fmt.Errorf("the value on line %d holds no other values", n.Line)
+		}
+	}
+	if n.Kind == yaml.AliasNode {
+		return nil, nil, fmt.Errorf("the value on line %d is an alias", n.Line)
+	}
+	return n, keys, nil
+}
+
+// yamlMappingIndex returns the index in a mapping node's content of the
+// key node for a key, or -1 if the mapping doesn't define the key itself
+func yamlMappingIndex(n *yaml.Node, key string) int {
+	c := newYAMLConverter()
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		if k.ShortTag() != "!!merge" && c.key(k) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// patchYAMLMapping applies a patch operation to the member of a mapping
+// node with a key. Setting a key that the mapping only has through a
+// merge key gives the mapping a key of its own
+func patchYAMLMapping(n *yaml.Node, op PatchOp, key string, keys []interface{}, tags *yamlTags) error {
+	i := yamlMappingIndex(n, key)
+	path := appendKey(keys, key)
+
+	if op.Op == "remove" {
+		if i < 0 {
+			return fmt.Errorf("`%s` comes from a merge key", key)
+		}
+		n.Content = append(n.Content[:i], n.Content[i+2:]...)
+		return nil
+	}
+
+	v, err := interfaceToNode(op.Value, tags, path)
+	if err != nil {
+		return err
+	}
+	if i >= 0 {
+		keepYAMLComments(n.Content[i+1], v)
+		n.Content[i+1] = v
+		return nil
+	}
+
+	k := &yaml.Node{}
+	k.SetString(key)
+	if !tags.empty() {
+		if tag, ok := tags.keys[jsonPointer(path)]; ok {
+			retag(k, tag)
+		}
+	}
+	n.Content = append(n.Content, k, v)
+	return nil
+}
+
+// patchYAMLSequence applies a patch operation to an element of a
+// sequence node
+func patchYAMLSequence(n *yaml.Node, op PatchOp, token string, keys []interface{}, tags *yamlTags) error {
+	i, err := arrayIndex(token, len(n.Content), op.Op == "add")
+	if err != nil {
+		return err
+	}
+
+	if op.Op == "remove" {
+		n.Content = append(n.Content[:i], n.Content[i+1:]...)
+		return nil
+	}
+
+	v, err := interfaceToNode(op.Value, tags, appendKey(keys, i))
+	if err != nil {
+		return err
+	}
+	if op.Op == "replace" {
+		keepYAMLComments(n.Content[i], v)
+		n.Content[i] = v
+		return nil
+	}
+	n.Content = append(n.Content[:i], append([]*yaml.Node{v}, n.Content[i:]...)...)
+	return nil
+}
+
+// keepYAMLComments gives a node that replaces another the comments of
+// the node it replaces, and its quoting style if they're both scalars
+// with the same tag
+func keepYAMLComments(old, n *yaml.Node) {
+	n.HeadComment = old.HeadComment
+	n.LineComment = old.LineComment
+	n.FootComment = old.FootComment
+	if old.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode && old.ShortTag() == n.ShortTag() {
+		n.Style = old.Style
+	}
+}
@@ -0,0 +1,229 @@
+package gron
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	json "github.com/virtuald/go-ordered-json"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLDecoderMatchesYAMLv3(t *testing.T) {
+	in := `name: gron
+defaults: &defaults
+  retries: 3
+  verbose: false
+jobs:
+  - <<: *defaults
+    name: build
+    retries: 5
+  - <<: [*defaults, {timeout: 10}]
+    name: test
+tags: [a, b]
+empty: ~
+`
+
+	var plain interface{}
+	err := yaml.NewDecoder(strings.NewReader(in)).Decode(&plain)
+	if err != nil {
+		t.Fatalf("want nil error from yaml.v3; have %s", err)
+	}
+	var ordered interface{}
+	err = newYAMLDecoder(strings.NewReader(in)).Decode(&ordered)
+	if err != nil {
+		t.Fatalf("want nil error from yamlDecoder; have %s", err)
+	}
+
+	gron := func(v interface{}) []string {
+		var ss Statements
+		ss.fill(Statement{{"json", TypBare}}, v)
+		sort.Sort(ss)
+		out := make([]string, len(ss))
+		for i, s := range ss {
+			out[i] = s.String()
+		}
+		return out
+	}
+	want, have := gron(plain), gron(ordered)
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Logf("want: %s", strings.Join(want, "\n"))
+		t.Logf("have: %s", strings.Join(have, "\n"))
+		t.Errorf("statements from yamlDecoder do not match yaml.v3")
+	}
+
+	// Unlike yaml.v3, the keys of each mapping keep their order
+	var keys []string
+	for _, m := range ordered.(json.OrderedObject) {
+		keys = append(keys, m.Key)
+	}
+	if strings.Join(keys, ",") != "name,defaults,jobs,tags,empty" {
+		t.Errorf("want keys in document order; have %s", keys)
+	}
+}
+
+func TestYAMLNumbers(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Token
+	}{
+		{"9007199254740993", Token{"9007199254740993", TypNumber}},
+		{"18446744073709551615", Token{"18446744073709551615", TypNumber}},
+		{"-9223372036854775808", Token{"-9223372036854775808", TypNumber}},
+		{"123456789012345678901234567890", Token{"123456789012345678901234567890", TypNumber}},
+		{"3.14159265358979323846264338327950288", Token{"3.14159265358979323846264338327950288", TypNumber}},
+		{"0.10", Token{"0.10", TypNumber}},
+		{"1e6", Token{"1e6", TypNumber}},
+		{"1.5E-10", Token{"1.5E-10", TypNumber}},
+		{"-2.5e+300", Token{"-2.5e+300", TypNumber}},
+		{"0x1F", Token{"31", TypNumber}},
+		{"0o17", Token{"15", TypNumber}},
+		{"+12", Token{"12", TypNumber}},
+		{"+.5", Token{"0.5", TypNumber}},
+		{"-.5e3", Token{"-0.5e3", TypNumber}},
+		{"1.", Token{"1.0", TypNumber}},
+		{".inf", Token{`".inf"`, TypString}},
+		{"-.Inf", Token{`"-.Inf"`, TypString}},
+		{".nan", Token{`".nan"`, TypString}},
+		{`"12"`, Token{`"12"`, TypString}},
+		{"!!float 12", Token{"12", TypNumber}},
+	}
+
+	for _, c := range cases {
+		var v interface{}
+		err := newYAMLDecoder(strings.NewReader(c.in)).Decode(&v)
+		if err != nil {
+			t.Fatalf("want nil error decoding `%s`; have %s", c.in, err)
+		}
+
+		have := valueTokenFromInterface(v)
+		if have != c.want {
+			t.Errorf("want %#v for `%s`; have %#v", c.want, c.in, have)
+		}
+	}
+}
+
+func TestYAMLNumbersRoundTrip(t *testing.T) {
+	in := "id: 18446744073709551615\nprice: 0.10\nbig: 1e400\n"
+
+	var v interface{}
+	err := newYAMLDecoder(strings.NewReader(in)).Decode(&v)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	out := &strings.Builder{}
+	err = encodeYAML(out, v)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if out.String() != in {
+		t.Errorf("want %q; have %q", in, out.String())
+	}
+}
+
+func TestYAMLTags(t *testing.T) {
+	in := `base: &base
+  created: 2001-12-14t21:59:43.10-05:00
+  ref: !Ref Bucket
+item:
+  <<: *base
+  ref: own
+  data: !!binary |
+    aGVsbG8g
+    d29ybGQ=
+  1: one
+  ? [x, y]
+  : complex
+  big: .inf
+`
+	want := []string{
+		`json = {};`,
+		`json.base = {};`,
+		`json.base.created = "2001-12-14t21:59:43.10-05:00"; // !!timestamp`,
+		`json.base.ref = "Bucket"; // !Ref`,
+		`json.item = {};`,
+		`json.item.created = "2001-12-14t21:59:43.10-05:00"; // !!timestamp`,
+		`json.item.ref = "own";`,
+		`json.item.data = "aGVsbG8gd29ybGQ="; // !!binary`,
+		`json.item["1"] = "one"; // key !!int`,
+		`json.item["[\"x\",\"y\"]"] = "complex";`,
+		`json.item.big = ".inf"; // !!float`,
+	}
+
+	ss, err := statementsFromDecoder(newYAMLDecoder(strings.NewReader(in)), Statement{{"json", TypBare}}, GronOptions{YAMLTags: true})
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	have := make([]string, len(ss))
+	for i, s := range ss {
+		have[i] = s.String()
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want:\n%s\nhave:\n%s", strings.Join(want, "\n"), strings.Join(have, "\n"))
+	}
+}
+
+func TestYAMLTagsRoundTrip(t *testing.T) {
+	in := `created: 2001-12-14t21:59:43.10-05:00
+ref: !Ref Bucket
+data: !!binary aGk=
+1: one
+2: !!binary aGk=
+big: .inf
+`
+
+	var v interface{}
+	d := newYAMLDecoder(strings.NewReader(in))
+	err := d.Decode(&v)
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+
+	ss := make(Statements, 0)
+	ss.fill(Statement{{"json", TypBare}}, v)
+	ss.annotateTags(1, d.tags)
+
+	out := &strings.Builder{}
+	err = encodeYAMLWithTags(out, v, ss.yamlTags())
+	if err != nil {
+		t.Fatalf("want nil error; have %s", err)
+	}
+	if out.String() != in {
+		t.Errorf("want %q; have %q", in, out.String())
+	}
+}
+
+func TestYAMLAliasCycle(t *testing.T) {
+	for _, in := range []string{
+		"a: &a [*a]\n",
+		"a: &a {b: *a}\n",
+		"a: &a {<<: *a}\n",
+	} {
+		var v interface{}
+		err := newYAMLDecoder(strings.NewReader(in)).Decode(&v)
+		if err == nil {
+			t.Errorf("want error decoding %q; have nil", in)
+		}
+	}
+}
+
+func TestYAMLAliasExpansionLimit(t *testing.T) {
+	in := `a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`
+	var v interface{}
+	err := newYAMLDecoder(strings.NewReader(in)).Decode(&v)
+	if err == nil {
+		t.Errorf("want error decoding billion laughs document; have nil")
+	}
+}